@@ -0,0 +1,24 @@
+//go:build go1.21
+// +build go1.21
+
+package httpx
+
+import "net/http"
+
+// ApplyN returns a RequestBuilder that calls factory with each of the values, in order, and applies
+// the resulting builders to the request, stopping at the first error. It avoids explicit loops when
+// adding multiple headers, cookies or query params from a slice, such as,
+//
+//  MakeRequest(Get("/search"), ApplyN([]*http.Cookie{session, csrf}, func(c *http.Cookie) RequestBuilder {
+//    return func(request *http.Request) error { request.AddCookie(c); return nil }
+//  }))
+func ApplyN[T any](values []T, factory func(T) RequestBuilder) RequestBuilder {
+	return func(request *http.Request) error {
+		for _, v := range values {
+			if err := factory(v)(request); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,44 @@
+//go:build go1.21
+// +build go1.21
+
+package httpx_test
+
+import (
+	"errors"
+	. "go.riyazali.net/httpx"
+	"net/http"
+	"testing"
+)
+
+func TestApplyN(t *testing.T) {
+	var header = func(v string) RequestBuilder {
+		return func(request *http.Request) error {
+			if v == "" {
+				return errors.New("empty value")
+			}
+			request.Header.Add("X-Tag", v)
+			return nil
+		}
+	}
+
+	t.Run("should apply builders in order", func(t *testing.T) {
+		var request, _ = http.NewRequest(http.MethodGet, "/", nil)
+		var err = ApplyN([]string{"go", "http", "test"}, header)(request)
+		assert(t, err == nil, "must not return error")
+
+		var values = request.Header["X-Tag"]
+		assert(t, len(values) == 3 && values[0] == "go" && values[1] == "http" && values[2] == "test", "must apply all builders in order")
+	})
+
+	t.Run("should stop at first error", func(t *testing.T) {
+		var request, _ = http.NewRequest(http.MethodGet, "/", nil)
+		var err = ApplyN([]string{"go", "", "test"}, header)(request)
+		assert(t, err != nil, "must return error")
+		assert(t, len(request.Header["X-Tag"]) == 1, "must not apply builders after error")
+	})
+
+	t.Run("should do nothing for no values", func(t *testing.T) {
+		var request, _ = http.NewRequest(http.MethodGet, "/", nil)
+		assert(t, ApplyN(nil, header)(request) == nil, "must not return error")
+	})
+}
@@ -0,0 +1,128 @@
+package assertions
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"go.riyazali.net/httpx"
+	. "go.riyazali.net/httpx/helpers"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// supported hash algorithms for ExpectResponseBodyHash
+var hashes = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// ExpectResponseBodyHash returns an assertion that computes the digest of the response body using
+// the given algorithm (one of md5, sha1, sha256 or sha512) and compares its hex encoded form to wantHex (ignoring case).
+func ExpectResponseBodyHash(algorithm string, wantHex string) httpx.Assertion {
+	var fn, ok = hashes[strings.ToLower(algorithm)]
+	if !ok {
+		return failed(fmt.Errorf("hash: unsupported algorithm '%s'", algorithm))
+	}
+
+	return BodyBytes(func(body []byte) error {
+		var h = fn()
+		_, _ = h.Write(body) // never returns an error
+		var got = hex.EncodeToString(h.Sum(nil))
+		if !strings.EqualFold(got, wantHex) {
+			return fmt.Errorf("hash: %s of body (%s) not equal to expected hash (%s)", algorithm, got, wantHex)
+		}
+		return nil
+	})
+}
+
+// ExpectBodyNotTruncated returns an assertion that checks that the response body
+// was not truncated by an ExecFn returned from httpx.ExecFn.WithResponseSizeLimit(...)
+func ExpectBodyNotTruncated() httpx.Assertion {
+	return func(response *http.Response) error {
+		return AssertThat(response.Header.Get(httpx.HeaderBodyTruncated) == "",
+			"body: response body was truncated")
+	}
+}
+
+// ExpectNoBodyOnSuccess returns an assertion that checks that 204 No Content and 205 Reset Content responses,
+// which must never include a body, have an empty body. Responses with any other status always pass.
+func ExpectNoBodyOnSuccess() httpx.Assertion {
+	return ExpectNoBodyFor(http.StatusNoContent, http.StatusResetContent)
+}
+
+// ExpectNoBodyFor returns an assertion that checks that responses with any of the given status codes
+// have an empty body, both as advertised by Content-Length and as read. Responses with any other status always pass.
+func ExpectNoBodyFor(codes ...int) httpx.Assertion {
+	return func(response *http.Response) (err error) {
+		defer checkClose(response.Body, &err)
+
+		var found = false
+		for _, code := range codes {
+			found = found || code == response.StatusCode
+		}
+		if !found {
+			return nil
+		}
+
+		if response.ContentLength > 0 {
+			return fmt.Errorf("body: response with status (%d) has a Content-Length of %d", response.StatusCode, response.ContentLength)
+		}
+		var n int64
+		if n, err = io.Copy(ioutil.Discard, response.Body); err != nil {
+			return fmt.Errorf("body: failed to read response body: %v", err)
+		}
+		return AssertThat(n == 0, "body: response with status (%d) has a body of %d bytes", response.StatusCode, n)
+	}
+}
+
+// ExpectResponseBodyDecompressedSize returns an assertion that decompresses the response body, as per its
+// Content-Encoding (either gzip or deflate), and checks that the decompressed body is wantBytes long.
+// It fails if the response isn't compressed, or uses an unsupported encoding (like br).
+//
+// Note that the default client transparently decompresses gzip responses (and removes the Content-Encoding header),
+// so use it with a client configured with executors.WithDisabledCompression().
+func ExpectResponseBodyDecompressedSize(wantBytes int64) httpx.Assertion {
+	return func(response *http.Response) (err error) {
+		defer checkClose(response.Body, &err)
+
+		var encoding = strings.ToLower(strings.TrimSpace(response.Header.Get("Content-Encoding")))
+		var reader io.Reader
+		switch encoding {
+		case "":
+			return fmt.Errorf("body: response is not compressed (no Content-Encoding)")
+		case "gzip", "x-gzip":
+			if reader, err = gzip.NewReader(response.Body); err != nil {
+				return fmt.Errorf("body: failed to decompress gzip body: %v", err)
+			}
+		case "deflate":
+			// deflate is supposed to be zlib wrapped, but some servers send raw deflate data instead
+			var body []byte
+			if body, err = ioutil.ReadAll(response.Body); err != nil {
+				return fmt.Errorf("body: failed to read response body: %v", err)
+			}
+			if reader, err = zlib.NewReader(bytes.NewReader(body)); err != nil {
+				reader, err = flate.NewReader(bytes.NewReader(body)), nil
+			}
+		default:
+			return fmt.Errorf("body: unsupported Content-Encoding '%s'", encoding)
+		}
+
+		var n int64
+		if n, err = io.Copy(ioutil.Discard, reader); err != nil {
+			return fmt.Errorf("body: failed to decompress %s body: %v", encoding, err)
+		}
+		return AssertThat(n == wantBytes, "body: decompressed size (%d bytes) not equal to expected (%d bytes)", n, wantBytes)
+	}
+}
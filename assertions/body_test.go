@@ -0,0 +1,105 @@
+package assertions_test
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"go.riyazali.net/httpx"
+	. "go.riyazali.net/httpx/assertions"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExpectResponseBodyHash(t *testing.T) {
+	var cases = map[string]string{
+		"md5":    "5eb63bbbe01eeed093cb22bb8f5acdc3",
+		"sha1":   "2aae6c35c94fcfb415dbe95f408b9ce91ee846ed",
+		"sha256": "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9",
+		"sha512": "309ecc489c12d6eb4cc40f50c902f2b4d0ed77ee511a7c7a9bcd3ca86d4cd86f989dd35bc5ff499670da34255b45b0cfd830e81f605dcf7dc5542e93ae9cd76f",
+	}
+
+	for algorithm, digest := range cases {
+		assert(t, ExpectResponseBodyHash(algorithm, digest)(withBody("hello world")) == nil, "%s: must pass for matching digest", algorithm)
+		assert(t, ExpectResponseBodyHash(algorithm, strings.ToUpper(digest))(withBody("hello world")) == nil, "%s: must ignore case", algorithm)
+		assert(t, ExpectResponseBodyHash(algorithm, digest)(withBody("hello")) != nil, "%s: must fail for different body", algorithm)
+	}
+
+	t.Run("should fail for unsupported algorithm", func(t *testing.T) {
+		assert(t, ExpectResponseBodyHash("crc32", "")(withBody("hello world")) != nil, "must fail for unsupported algorithm")
+	})
+
+	t.Run("should report actual hash", func(t *testing.T) {
+		var err = ExpectResponseBodyHash("md5", "00")(withBody("hello world"))
+		assert(t, err != nil && strings.Contains(err.Error(), cases["md5"]), "must include actual hash in error")
+	})
+}
+
+func TestExpectBodyNotTruncated(t *testing.T) {
+	var resp = withBody("hello world")
+	assert(t, ExpectBodyNotTruncated()(resp) == nil, "must pass if body is not truncated")
+
+	resp.Header.Set(httpx.HeaderBodyTruncated, "true")
+	assert(t, ExpectBodyNotTruncated()(resp) != nil, "must fail if body is truncated")
+}
+
+func TestExpectNoBodyOnSuccess(t *testing.T) {
+	var response = func(status int, body string) *http.Response {
+		var writer = httptest.NewRecorder()
+		writer.WriteHeader(status)
+		_, _ = io.WriteString(writer, body)
+		return writer.Result()
+	}
+
+	assert(t, ExpectNoBodyOnSuccess()(response(http.StatusNoContent, "")) == nil, "must pass for empty 204 response")
+	assert(t, ExpectNoBodyOnSuccess()(response(http.StatusResetContent, "")) == nil, "must pass for empty 205 response")
+	assert(t, ExpectNoBodyOnSuccess()(response(http.StatusNoContent, "oops")) != nil, "must fail for 204 response with body")
+	assert(t, ExpectNoBodyOnSuccess()(response(http.StatusResetContent, "oops")) != nil, "must fail for 205 response with body")
+	assert(t, ExpectNoBodyOnSuccess()(response(http.StatusOK, "hello")) == nil, "must skip other responses")
+
+	var withLength = response(http.StatusNoContent, "")
+	withLength.ContentLength = 4
+	assert(t, ExpectNoBodyOnSuccess()(withLength) != nil, "must fail for non-zero Content-Length")
+
+	t.Run("should check custom codes", func(t *testing.T) {
+		assert(t, ExpectNoBodyFor(http.StatusNotModified)(response(http.StatusNotModified, "")) == nil, "must pass for empty body")
+		assert(t, ExpectNoBodyFor(http.StatusNotModified)(response(http.StatusNotModified, "stale")) != nil, "must fail for body")
+		assert(t, ExpectNoBodyFor(http.StatusNotModified)(response(http.StatusNoContent, "oops")) == nil, "must skip codes not given")
+	})
+}
+
+func TestExpectResponseBodyDecompressedSize(t *testing.T) {
+	var payload = strings.Repeat("hello world ", 100) // 1200 bytes
+	var response = func(encoding string, compress func(io.Writer) io.WriteCloser) *http.Response {
+		var writer = httptest.NewRecorder()
+		if encoding != "" {
+			writer.Header().Set("Content-Encoding", encoding)
+		}
+		if compress == nil {
+			_, _ = io.WriteString(writer, payload)
+			return writer.Result()
+		}
+		var w = compress(writer)
+		_, _ = io.WriteString(w, payload)
+		_ = w.Close()
+		return writer.Result()
+	}
+
+	var gz = func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }
+	var zl = func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) }
+	var raw = func(w io.Writer) io.WriteCloser {
+		var fw, _ = flate.NewWriter(w, flate.DefaultCompression) // never fails for a valid level
+		return fw
+	}
+
+	assert(t, ExpectResponseBodyDecompressedSize(1200)(response("gzip", gz)) == nil, "must pass for gzip body")
+	assert(t, ExpectResponseBodyDecompressedSize(1200)(response("deflate", zl)) == nil, "must pass for zlib deflate body")
+	assert(t, ExpectResponseBodyDecompressedSize(1200)(response("deflate", raw)) == nil, "must pass for raw deflate body")
+	assert(t, ExpectResponseBodyDecompressedSize(100)(response("gzip", gz)) != nil, "must fail for different size")
+
+	assert(t, ExpectResponseBodyDecompressedSize(1200)(response("", nil)) != nil, "must fail for uncompressed body")
+	assert(t, ExpectResponseBodyDecompressedSize(1200)(response("gzip", nil)) != nil, "must fail for invalid gzip body")
+	assert(t, ExpectResponseBodyDecompressedSize(1200)(response("br", nil)) != nil, "must fail for unsupported encoding")
+}
@@ -0,0 +1,90 @@
+package assertions
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"fmt"
+	"go.riyazali.net/httpx"
+	. "go.riyazali.net/httpx/helpers"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ExpectCookieMaxAge returns an assertion that checks that the lifetime of the named cookie is within [minAge, maxAge].
+// The lifetime is read from the cookie's Max-Age attribute or, if that's not set, derived from its Expires attribute.
+func ExpectCookieMaxAge(name string, minAge, maxAge time.Duration) httpx.Assertion {
+	return WithCookie(name, func(c *http.Cookie) error {
+		if c == nil {
+			return fmt.Errorf("cookie with name '%s' not set", name)
+		}
+		var age, ok = cookieAge(c)
+		if !ok {
+			return fmt.Errorf("cookie '%s' has neither Max-Age nor Expires set", name)
+		}
+		if age < minAge || age > maxAge {
+			return fmt.Errorf("max-age of cookie '%s' (%s) not within [%s, %s]", name, age, minAge, maxAge)
+		}
+		return nil
+	})
+}
+
+// ExpectCookieExpires returns an assertion that checks that the named cookie expires after the given
+// time and before the other. If the cookie has a Max-Age attribute, it takes precedence over Expires (per RFC 6265).
+func ExpectCookieExpires(name string, after, before time.Time) httpx.Assertion {
+	return WithCookie(name, func(c *http.Cookie) error {
+		if c == nil {
+			return fmt.Errorf("cookie with name '%s' not set", name)
+		}
+
+		var expires = c.Expires
+		if c.MaxAge != 0 {
+			var age, _ = cookieAge(c)
+			expires = time.Now().Add(age)
+		} else if expires.IsZero() {
+			return fmt.Errorf("cookie '%s' has neither Max-Age nor Expires set", name)
+		}
+
+		if !expires.After(after) || !expires.Before(before) {
+			return fmt.Errorf("expiry of cookie '%s' (%s) not within (%s, %s)", name,
+				expires.Format(time.RFC3339), after.Format(time.RFC3339), before.Format(time.RFC3339))
+		}
+		return nil
+	})
+}
+
+// cookieAge returns the lifetime of the cookie, giving precedence to Max-Age over Expires (per RFC 6265)
+func cookieAge(c *http.Cookie) (time.Duration, bool) {
+	if c.MaxAge > 0 {
+		return time.Duration(c.MaxAge) * time.Second, true
+	} else if c.MaxAge < 0 {
+		return 0, true // Max-Age=0 in the header, i.e. expire immediately
+	} else if !c.Expires.IsZero() {
+		return time.Until(c.Expires), true
+	}
+	return 0, false
+}
+
+// ExpectSignedCookieValid returns an assertion that checks that the named cookie set by the response carries a valid
+// signature, as computed by helpers.SignCookie(...) using the given secret and hash function.
+func ExpectSignedCookieValid(name, secret string, hashFunc crypto.Hash) httpx.Assertion {
+	return WithCookie(name, func(c *http.Cookie) error {
+		if c == nil {
+			return fmt.Errorf("cookie with name '%s' not set", name)
+		}
+
+		var sep = strings.LastIndex(c.Value, "|")
+		if sep < 0 {
+			return fmt.Errorf("cookie '%s' is not signed", name)
+		}
+
+		var want, err = SignCookie(name, c.Value[:sep], secret, hashFunc)
+		if err != nil {
+			return err
+		}
+		if !hmac.Equal([]byte(want), []byte(c.Value)) {
+			return fmt.Errorf("cookie '%s' has an invalid signature", name)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,58 @@
+package assertions_test
+
+import (
+	"crypto"
+	. "go.riyazali.net/httpx/assertions"
+	. "go.riyazali.net/httpx/helpers"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestExpectCookieMaxAge(t *testing.T) {
+	var writer = httptest.NewRecorder()
+	http.SetCookie(writer, &http.Cookie{Name: "session", Value: "1", MaxAge: 3600})
+	http.SetCookie(writer, &http.Cookie{Name: "legacy", Value: "1", Expires: time.Now().Add(2 * time.Hour)})
+	http.SetCookie(writer, &http.Cookie{Name: "both", Value: "1", MaxAge: 60, Expires: time.Now().Add(48 * time.Hour)})
+	http.SetCookie(writer, &http.Cookie{Name: "transient", Value: "1"})
+	var resp = writer.Result()
+
+	assert(t, ExpectCookieMaxAge("session", 59*time.Minute, time.Hour)(resp) == nil, "must pass if max-age is in range")
+	assert(t, ExpectCookieMaxAge("session", 2*time.Hour, 3*time.Hour)(resp) != nil, "must fail if max-age is out of range")
+	assert(t, ExpectCookieMaxAge("legacy", time.Hour, 2*time.Hour)(resp) == nil, "must derive max-age from expires")
+	assert(t, ExpectCookieMaxAge("both", 0, time.Minute)(resp) == nil, "max-age must take precedence over expires")
+	assert(t, ExpectCookieMaxAge("transient", 0, time.Hour)(resp) != nil, "must fail for session cookie")
+	assert(t, ExpectCookieMaxAge("missing", 0, time.Hour)(resp) != nil, "must fail for missing cookie")
+}
+
+func TestExpectCookieExpires(t *testing.T) {
+	var now = time.Now()
+	var writer = httptest.NewRecorder()
+	http.SetCookie(writer, &http.Cookie{Name: "legacy", Value: "1", Expires: now.Add(2 * time.Hour)})
+	http.SetCookie(writer, &http.Cookie{Name: "both", Value: "1", MaxAge: 60, Expires: now.Add(48 * time.Hour)})
+	http.SetCookie(writer, &http.Cookie{Name: "transient", Value: "1"})
+	var resp = writer.Result()
+
+	assert(t, ExpectCookieExpires("legacy", now.Add(time.Hour), now.Add(3*time.Hour))(resp) == nil, "must pass if expiry is in range")
+	assert(t, ExpectCookieExpires("legacy", now.Add(3*time.Hour), now.Add(4*time.Hour))(resp) != nil, "must fail if expiry is out of range")
+	assert(t, ExpectCookieExpires("both", now, now.Add(2*time.Minute))(resp) == nil, "max-age must take precedence over expires")
+	assert(t, ExpectCookieExpires("transient", now, now.Add(time.Hour))(resp) != nil, "must fail for session cookie")
+	assert(t, ExpectCookieExpires("missing", now, now.Add(time.Hour))(resp) != nil, "must fail for missing cookie")
+}
+
+func TestExpectSignedCookieValid(t *testing.T) {
+	var signed, _ = SignCookie("session", "user-1", "s3cr3t", crypto.SHA256)
+	var writer = httptest.NewRecorder()
+	http.SetCookie(writer, &http.Cookie{Name: "session", Value: signed})
+	http.SetCookie(writer, &http.Cookie{Name: "tampered", Value: "user-2" + signed[len("user-1"):]})
+	http.SetCookie(writer, &http.Cookie{Name: "plain", Value: "user-1"})
+	var resp = writer.Result()
+
+	assert(t, ExpectSignedCookieValid("session", "s3cr3t", crypto.SHA256)(resp) == nil, "must pass for valid signature")
+	assert(t, ExpectSignedCookieValid("session", "other", crypto.SHA256)(resp) != nil, "must fail for different secret")
+	assert(t, ExpectSignedCookieValid("session", "s3cr3t", crypto.SHA512)(resp) != nil, "must fail for different hash function")
+	assert(t, ExpectSignedCookieValid("tampered", "s3cr3t", crypto.SHA256)(resp) != nil, "must fail for tampered value")
+	assert(t, ExpectSignedCookieValid("plain", "s3cr3t", crypto.SHA256)(resp) != nil, "must fail for unsigned cookie")
+	assert(t, ExpectSignedCookieValid("missing", "s3cr3t", crypto.SHA256)(resp) != nil, "must fail for missing cookie")
+}
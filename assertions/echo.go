@@ -0,0 +1,80 @@
+package assertions
+
+import (
+	"encoding/json"
+	"fmt"
+	"go.riyazali.net/httpx"
+	. "go.riyazali.net/httpx/helpers"
+	"net/http"
+	"strings"
+)
+
+// echo is the response body returned by echo servers (and debugging proxies) that reflect the request they received
+type echo struct {
+	Method  string                     `json:"method"`
+	Headers map[string]json.RawMessage `json:"headers"`
+}
+
+// ExpectResponseEchosMethod returns an assertion that checks that the method reflected in an echo response body
+// (of form {"method": "...", "headers": {...}, "body": ...}) matches the method of the request that was made.
+// This is useful to verify that reverse proxies and api gateways forward requests unchanged.
+func ExpectResponseEchosMethod() httpx.Assertion {
+	return withEcho(func(e *echo, request *http.Request) error {
+		return AssertThat(strings.EqualFold(e.Method, request.Method),
+			"echo: method (%s) not equal to requested method (%s)", e.Method, request.Method)
+	})
+}
+
+// ExpectResponseEchosHeader returns an assertion that checks that the values of header key reflected
+// in an echo response body (see ExpectResponseEchosMethod) match the ones sent with the request that was made.
+// Header names are matched ignoring case, and echoed values may either be a string or an array of strings.
+func ExpectResponseEchosHeader(key string) httpx.Assertion {
+	return withEcho(func(e *echo, request *http.Request) error {
+		var want = request.Header[http.CanonicalHeaderKey(key)]
+		if len(want) == 0 {
+			return fmt.Errorf("echo: request has no '%s' header", key)
+		}
+
+		var raw json.RawMessage
+		var found = false
+		for k, v := range e.Headers {
+			if strings.EqualFold(k, key) {
+				raw, found = v, true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("echo: header '%s' not echoed", key)
+		}
+
+		var got []string
+		if err := json.Unmarshal(raw, &got); err != nil {
+			var single string
+			if err = json.Unmarshal(raw, &single); err != nil {
+				return fmt.Errorf("echo: header '%s' has value (%s) that is neither a string nor an array of strings", key, raw)
+			}
+			got = []string{single}
+		}
+
+		return AssertThat(strings.Join(got, ", ") == strings.Join(want, ", "),
+			"echo: header '%s' value (%s) not equal to requested value (%s)", key, strings.Join(got, ", "), strings.Join(want, ", "))
+	})
+}
+
+// withEcho returns an assertion that decodes the echo response body and invokes the callback with it
+// and the request associated with the response.
+func withEcho(cb func(*echo, *http.Request) error) httpx.Assertion {
+	return func(response *http.Response) (err error) {
+		defer checkClose(response.Body, &err)
+
+		if response.Request == nil {
+			return fmt.Errorf("echo: response has no request associated with it")
+		}
+
+		var e echo
+		if err := json.NewDecoder(response.Body).Decode(&e); err != nil {
+			return fmt.Errorf("echo: failed to decode response body: %v", err)
+		}
+		return cb(&e, response.Request)
+	}
+}
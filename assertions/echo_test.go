@@ -0,0 +1,42 @@
+package assertions_test
+
+import (
+	. "go.riyazali.net/httpx/assertions"
+	"net/http"
+	"testing"
+)
+
+func TestExpectResponseEchos(t *testing.T) {
+	var echoed = func(method string, body string) *http.Response {
+		var request, _ = http.NewRequest(method, "http://example.com/echo", nil)
+		request.Header.Set("X-Request-Id", "abc")
+		request.Header.Add("Accept", "text/plain")
+		request.Header.Add("Accept", "application/json")
+
+		var response = withBody(body)
+		response.Request = request
+		return response
+	}
+
+	var body = `{"method": "POST", "headers": {"x-request-id": "abc", "Accept": ["text/plain", "application/json"]}, "body": ""}`
+
+	t.Run("should compare method", func(t *testing.T) {
+		assert(t, ExpectResponseEchosMethod()(echoed(http.MethodPost, body)) == nil, "must pass for same method")
+		assert(t, ExpectResponseEchosMethod()(echoed(http.MethodGet, body)) != nil, "must fail for different method")
+	})
+
+	t.Run("should compare header", func(t *testing.T) {
+		assert(t, ExpectResponseEchosHeader("X-Request-Id")(echoed(http.MethodPost, body)) == nil, "must pass for string value")
+		assert(t, ExpectResponseEchosHeader("accept")(echoed(http.MethodPost, body)) == nil, "must pass for array value")
+		assert(t, ExpectResponseEchosHeader("X-Request-Id")(echoed(http.MethodPost, `{"headers": {"X-Request-Id": "xyz"}}`)) != nil,
+			"must fail for different value")
+		assert(t, ExpectResponseEchosHeader("X-Request-Id")(echoed(http.MethodPost, `{"headers": {}}`)) != nil,
+			"must fail if header is not echoed")
+		assert(t, ExpectResponseEchosHeader("X-Missing")(echoed(http.MethodPost, body)) != nil, "must fail if request has no such header")
+	})
+
+	t.Run("should fail for invalid response", func(t *testing.T) {
+		assert(t, ExpectResponseEchosMethod()(withBody(body)) != nil, "must fail if response has no request")
+		assert(t, ExpectResponseEchosMethod()(echoed(http.MethodPost, `not json`)) != nil, "must fail for invalid json")
+	})
+}
@@ -0,0 +1,275 @@
+package assertions
+
+import (
+	"encoding/hex"
+	"fmt"
+	"go.riyazali.net/httpx"
+	. "go.riyazali.net/httpx/helpers"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultSensitiveHeaders is the list of headers checked by ExpectNoSensitiveHeaders(...).
+// Set-Cookie is deliberately left out as it's commonly set intentionally; pass it explicitly if it must not be present.
+var DefaultSensitiveHeaders = []string{"Authorization", "Cookie", "X-Internal-*"}
+
+// ExpectNoSensitiveHeaders returns an assertion that checks that none of the DefaultSensitiveHeaders, or
+// any of the additional sensitiveKeys, are present in the response. Keys are matched case-insensitively
+// and a key ending with '*' matches all headers with the given prefix.
+func ExpectNoSensitiveHeaders(sensitiveKeys ...string) httpx.Assertion {
+	var keys = append(append([]string(nil), DefaultSensitiveHeaders...), sensitiveKeys...)
+	return func(response *http.Response) error {
+		var leaked []string
+		for name := range response.Header {
+			for _, key := range keys {
+				if matchHeader(key, name) {
+					leaked = append(leaked, name)
+					break
+				}
+			}
+		}
+
+		if len(leaked) > 0 {
+			sort.Strings(leaked) // header is a map; sort for a stable message
+			return fmt.Errorf("header: sensitive header(s) found in response: %s", strings.Join(leaked, ", "))
+		}
+		return nil
+	}
+}
+
+// matchHeader reports whether the header name matches the key, which may end with a '*' wildcard
+func matchHeader(key, name string) bool {
+	if strings.HasSuffix(key, "*") {
+		var prefix = strings.TrimSuffix(key, "*")
+		return len(name) >= len(prefix) && strings.EqualFold(name[:len(prefix)], prefix)
+	}
+	return strings.EqualFold(key, name)
+}
+
+// ExpectAtLeastOneHeader returns an assertion that checks that at least one of the given headers
+// is present (with a non-empty value) in the response. This is handy when equivalent headers are
+// returned under different names, say X-Request-ID and Request-ID.
+func ExpectAtLeastOneHeader(keys ...string) httpx.Assertion {
+	return func(response *http.Response) error {
+		for _, key := range keys {
+			if response.Header.Get(key) != "" {
+				return nil
+			}
+		}
+		return fmt.Errorf("header: none of the headers found: %s", strings.Join(keys, ", "))
+	}
+}
+
+// ExpectAtLeastOneHeaderWithValue is like ExpectAtLeastOneHeader but also checks that the header has the given value.
+func ExpectAtLeastOneHeaderWithValue(value string, keys ...string) httpx.Assertion {
+	return func(response *http.Response) error {
+		for _, key := range keys {
+			if v := response.Header.Get(key); v != "" && v == value {
+				return nil
+			}
+		}
+		return fmt.Errorf("header: none of the headers found with value '%s': %s", value, strings.Join(keys, ", "))
+	}
+}
+
+// ExpectXContentTypeNoSniff returns an assertion that checks that the X-Content-Type-Options header is set to nosniff
+func ExpectXContentTypeNoSniff() httpx.Assertion {
+	return WithHeader("X-Content-Type-Options", func(value string) error {
+		return AssertThat(strings.EqualFold(strings.TrimSpace(value), "nosniff"),
+			"X-Content-Type-Options (%s) not equal to expected value (nosniff)", value)
+	})
+}
+
+// ExpectXFrameOptions returns an assertion that checks the X-Frame-Options header. The wanted value
+// must be one of DENY, SAMEORIGIN or ALLOW-FROM <uri>; the directive is matched case-insensitively.
+func ExpectXFrameOptions(want string) httpx.Assertion {
+	var directive, uri = parseFrameOptions(want)
+	if directive != "DENY" && directive != "SAMEORIGIN" && (directive != "ALLOW-FROM" || uri == "") {
+		return failed(fmt.Errorf("header: invalid X-Frame-Options value '%s'", want))
+	}
+
+	return WithHeader("X-Frame-Options", func(value string) error {
+		if value == "" {
+			return fmt.Errorf("X-Frame-Options not found")
+		}
+		var gotDirective, gotUri = parseFrameOptions(value)
+		return AssertThat(gotDirective == directive && gotUri == uri,
+			"X-Frame-Options (%s) not equal to expected value (%s)", value, want)
+	})
+}
+
+// parseFrameOptions splits the X-Frame-Options value into an uppercase directive and an optional uri
+func parseFrameOptions(value string) (directive, uri string) {
+	var parts = strings.Fields(value)
+	if len(parts) == 0 {
+		return "", ""
+	}
+	directive = strings.ToUpper(parts[0])
+	if len(parts) > 1 {
+		uri = parts[1]
+	}
+	return directive, uri
+}
+
+// ExpectHTTPMethodsAllowed returns an assertion that checks that the Allow header lists exactly the given methods
+// (in any order). It is meant to be used with responses to OPTIONS requests, or with 405 Method Not Allowed
+// responses (which must include an Allow header), such as,
+//    MakeRequest(Using(http.MethodOptions, "/users", nil)).ExpectIt(t, ExpectHTTPMethodsAllowed("GET", "POST"))
+func ExpectHTTPMethodsAllowed(methods ...string) httpx.Assertion {
+	return func(response *http.Response) error {
+		if response.StatusCode != http.StatusMethodNotAllowed && (response.StatusCode < 200 || response.StatusCode > 299) {
+			return fmt.Errorf("allow: unexpected status (%d), expected a 2xx or 405 response", response.StatusCode)
+		}
+
+		var allow = response.Header.Get("Allow")
+		if allow == "" {
+			return fmt.Errorf("allow: header not found in response with status (%d)", response.StatusCode)
+		}
+
+		var want, got = methodSet(methods), methodSet(strings.Split(allow, ","))
+		var missing, extra []string
+		for m := range want {
+			if !got[m] {
+				missing = append(missing, m)
+			}
+		}
+		for m := range got {
+			if !want[m] {
+				extra = append(extra, m)
+			}
+		}
+		if len(missing) > 0 || len(extra) > 0 {
+			sort.Strings(missing)
+			sort.Strings(extra)
+			return fmt.Errorf("allow: allowed methods (%s) do not match, missing: [%s], unexpected: [%s]",
+				allow, strings.Join(missing, ", "), strings.Join(extra, ", "))
+		}
+		return nil
+	}
+}
+
+// methodSet returns a set of upper-cased, non-empty methods
+func methodSet(methods []string) map[string]bool {
+	var set = make(map[string]bool, len(methods))
+	for _, m := range methods {
+		if m = strings.ToUpper(strings.TrimSpace(m)); m != "" {
+			set[m] = true
+		}
+	}
+	return set
+}
+
+// ExpectHeaderOrdering returns an assertion that checks that the given headers were received in the given (relative) order.
+// Other headers may appear in between them.
+//
+// Since Go's http.Header is a map, the order in which headers appear on the wire is lost by the time the response
+// reaches an assertion. This assertion therefore relies on the order recorded in httpx.ResponseInfo
+// by a client configured with executors.WithHeaderOrder(), and fails if no order was recorded. Note that this is
+// only possible with plain-text HTTP/1.x connections, and not with in-memory handlers, TLS or HTTP/2.
+func ExpectHeaderOrdering(keys ...string) httpx.Assertion {
+	return func(response *http.Response) error {
+		var info = httpx.ResponseInfoOf(response)
+		if info == nil || info.HeaderOrder == nil {
+			return fmt.Errorf("header: order of headers wasn't recorded, make sure to use executors.WithHeaderOrder()")
+		}
+		var order = strings.Join(info.HeaderOrder, ", ")
+
+		var positions = make(map[string]int)
+		for i, name := range info.HeaderOrder {
+			positions[http.CanonicalHeaderKey(name)] = i
+		}
+
+		var prev = -1
+		for _, key := range keys {
+			var pos, ok = positions[http.CanonicalHeaderKey(key)]
+			if !ok {
+				return fmt.Errorf("header: %s not found in response", key)
+			}
+			if pos < prev {
+				return fmt.Errorf("header: %s is out of order, headers were received as (%s)", key, order)
+			}
+			prev = pos
+		}
+		return nil
+	}
+}
+
+// ExpectConnectionReuse returns an assertion that checks whether the response was received over a reused connection.
+// It relies on httpx.ResponseInfo recorded by a client configured with executors.WithKeepAlive()
+// (or executors.WithNoKeepAlive()), and fails if that wasn't recorded.
+func ExpectConnectionReuse(wantReused bool) httpx.Assertion {
+	return func(response *http.Response) error {
+		var info = httpx.ResponseInfoOf(response)
+		if info == nil || info.ConnectionReused == nil {
+			return fmt.Errorf("connection: reuse of connection wasn't recorded, make sure to use executors.WithKeepAlive()")
+		}
+		var reused = *info.ConnectionReused
+		return AssertThat(reused == wantReused, "connection: connection reused (%t) not equal to expected (%t)", reused, wantReused)
+	}
+}
+
+// traceParentPattern matches a version 00 W3C traceparent header (see https://www.w3.org/TR/trace-context/)
+var traceParentPattern = regexp.MustCompile(`^00-([0-9a-f]{32})-([0-9a-f]{16})-[0-9a-f]{2}$`)
+
+// ExpectTraceParent returns an assertion that checks that the response has a valid W3C traceparent header,
+// such as "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01". All zero trace and parent ids are invalid.
+func ExpectTraceParent() httpx.Assertion {
+	return func(response *http.Response) error {
+		var _, err = traceID(response)
+		return err
+	}
+}
+
+// ExpectSameTraceID returns an assertion that checks that the response's traceparent header carries the given trace id,
+// confirming that the server propagated the trace context sent with the request (say, using builders.WithTraceID(...))
+func ExpectSameTraceID(requestTraceID [16]byte) httpx.Assertion {
+	return func(response *http.Response) error {
+		var id, err = traceID(response)
+		if err != nil {
+			return err
+		}
+		if want := hex.EncodeToString(requestTraceID[:]); id != want {
+			return fmt.Errorf("traceparent: trace id (%s) not equal to expected (%s)", id, want)
+		}
+		return nil
+	}
+}
+
+// traceID validates the response's traceparent header and returns the trace id in it
+func traceID(response *http.Response) (string, error) {
+	var header = response.Header.Get("traceparent")
+	if header == "" {
+		return "", fmt.Errorf("traceparent: header not found in response")
+	}
+
+	var match = traceParentPattern.FindStringSubmatch(header)
+	if match == nil {
+		return "", fmt.Errorf("traceparent: header (%s) is not in a valid format", header)
+	}
+	if strings.Trim(match[1], "0") == "" || strings.Trim(match[2], "0") == "" {
+		return "", fmt.Errorf("traceparent: header (%s) has an all zero trace or parent id", header)
+	}
+	return match[1], nil
+}
+
+// ExpectHeaderAbsent returns an assertion that checks that the header with the given name is not present in the response,
+// say, after it has been stripped by some security middleware. See ExpectNoHeaders(...) to check multiple headers at once.
+func ExpectHeaderAbsent(key string) httpx.Assertion {
+	return ExpectNoHeaders(key)
+}
+
+// ExpectNoHeaders returns an assertion that checks that none of the headers with the given names (like X-Powered-By
+// or Server) are present in the response, reporting all the ones found. A header is present even if it has an empty value.
+func ExpectNoHeaders(keys ...string) httpx.Assertion {
+	return func(response *http.Response) error {
+		var present []string
+		for _, key := range keys {
+			if _, ok := response.Header[http.CanonicalHeaderKey(key)]; ok {
+				present = append(present, key)
+			}
+		}
+		return AssertThat(len(present) == 0, "header: unexpected headers found in response: [%s]", strings.Join(present, ", "))
+	}
+}
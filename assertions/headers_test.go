@@ -0,0 +1,141 @@
+package assertions_test
+
+import (
+	"go.riyazali.net/httpx"
+	. "go.riyazali.net/httpx/assertions"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// helper function to build a response with the given headers
+func withHeaders(kv ...string) *http.Response {
+	var writer = httptest.NewRecorder()
+	for i := 0; i+1 < len(kv); i += 2 {
+		writer.Header().Add(kv[i], kv[i+1])
+	}
+	return writer.Result()
+}
+
+func TestExpectNoSensitiveHeaders(t *testing.T) {
+	assert(t, ExpectNoSensitiveHeaders()(withHeaders("Content-Type", "text/plain")) == nil, "must pass without sensitive headers")
+	assert(t, ExpectNoSensitiveHeaders()(withHeaders("Set-Cookie", "a=1")) == nil, "must not check Set-Cookie by default")
+	assert(t, ExpectNoSensitiveHeaders()(withHeaders("authorization", "Bearer x")) != nil, "must fail for Authorization header")
+	assert(t, ExpectNoSensitiveHeaders()(withHeaders("Cookie", "a=1")) != nil, "must fail for Cookie header")
+	assert(t, ExpectNoSensitiveHeaders()(withHeaders("X-Internal-Node", "10.0.0.1")) != nil, "must fail for wildcard match")
+	assert(t, ExpectNoSensitiveHeaders("Set-Cookie")(withHeaders("Set-Cookie", "a=1")) != nil, "must check additional keys")
+	assert(t, ExpectNoSensitiveHeaders("X-Secret-*")(withHeaders("x-secret-token", "1")) != nil, "must support wildcard in additional keys")
+
+	var err = ExpectNoSensitiveHeaders()(withHeaders("X-Internal-B", "1", "Cookie", "a=1", "Authorization", "x", "X-Internal-A", "2"))
+	assert(t, err != nil && strings.HasSuffix(err.Error(), "Authorization, Cookie, X-Internal-A, X-Internal-B"),
+		"must list leaked headers in sorted order, got: %v", err)
+}
+
+func TestExpectAtLeastOneHeader(t *testing.T) {
+	var resp = withHeaders("Request-ID", "abc")
+	assert(t, ExpectAtLeastOneHeader("X-Request-ID", "Request-ID")(resp) == nil, "must pass if any header is present")
+	assert(t, ExpectAtLeastOneHeader("X-Request-ID", "X-Correlation-ID")(resp) != nil, "must fail if no header is present")
+	assert(t, ExpectAtLeastOneHeader()(resp) != nil, "must fail if no keys are given")
+
+	assert(t, ExpectAtLeastOneHeaderWithValue("abc", "X-Request-ID", "Request-ID")(resp) == nil, "must pass if any header has value")
+	assert(t, ExpectAtLeastOneHeaderWithValue("xyz", "X-Request-ID", "Request-ID")(resp) != nil, "must fail if no header has value")
+}
+
+func TestExpectXContentTypeNoSniff(t *testing.T) {
+	assert(t, ExpectXContentTypeNoSniff()(withHeaders("X-Content-Type-Options", "nosniff")) == nil, "must pass for nosniff")
+	assert(t, ExpectXContentTypeNoSniff()(withHeaders("X-Content-Type-Options", "NoSniff")) == nil, "must ignore case")
+	assert(t, ExpectXContentTypeNoSniff()(withHeaders()) != nil, "must fail if header is absent")
+}
+
+func TestExpectXFrameOptions(t *testing.T) {
+	assert(t, ExpectXFrameOptions("DENY")(withHeaders("X-Frame-Options", "deny")) == nil, "must pass for DENY")
+	assert(t, ExpectXFrameOptions("SAMEORIGIN")(withHeaders("X-Frame-Options", "SAMEORIGIN")) == nil, "must pass for SAMEORIGIN")
+	assert(t, ExpectXFrameOptions("ALLOW-FROM https://example.com")(withHeaders("X-Frame-Options", "allow-from https://example.com")) == nil,
+		"must pass for ALLOW-FROM with same uri")
+	assert(t, ExpectXFrameOptions("ALLOW-FROM https://example.com")(withHeaders("X-Frame-Options", "ALLOW-FROM https://other.com")) != nil,
+		"must fail for ALLOW-FROM with different uri")
+	assert(t, ExpectXFrameOptions("DENY")(withHeaders("X-Frame-Options", "SAMEORIGIN")) != nil, "must fail for different value")
+	assert(t, ExpectXFrameOptions("DENY")(withHeaders()) != nil, "must fail if header is absent")
+	assert(t, ExpectXFrameOptions("ALLOW")(withHeaders("X-Frame-Options", "ALLOW")) != nil, "must fail for invalid expected value")
+	assert(t, ExpectXFrameOptions("ALLOW-FROM")(withHeaders("X-Frame-Options", "ALLOW-FROM")) != nil, "must fail for ALLOW-FROM without uri")
+}
+
+func TestExpectHTTPMethodsAllowed(t *testing.T) {
+	var response = func(status int, kv ...string) *http.Response {
+		var resp = withHeaders(kv...)
+		resp.StatusCode = status
+		return resp
+	}
+
+	assert(t, ExpectHTTPMethodsAllowed("GET", "POST")(response(http.StatusOK, "Allow", "POST, GET")) == nil, "must pass for same methods")
+	assert(t, ExpectHTTPMethodsAllowed("get", "post")(response(http.StatusNoContent, "Allow", "GET,POST")) == nil, "must ignore case and spacing")
+	assert(t, ExpectHTTPMethodsAllowed("GET")(response(http.StatusMethodNotAllowed, "Allow", "GET")) == nil, "must pass for 405 response")
+	assert(t, ExpectHTTPMethodsAllowed("GET")(response(http.StatusOK, "Allow", "GET, DELETE")) != nil, "must fail for unexpected method")
+	assert(t, ExpectHTTPMethodsAllowed("GET", "PUT")(response(http.StatusOK, "Allow", "GET")) != nil, "must fail for missing method")
+	assert(t, ExpectHTTPMethodsAllowed("GET")(response(http.StatusMethodNotAllowed)) != nil, "must fail for 405 without Allow")
+	assert(t, ExpectHTTPMethodsAllowed("GET")(response(http.StatusNotFound, "Allow", "GET")) != nil, "must fail for other statuses")
+}
+
+// helper function to build a response with the given info recorded
+func withInfo(info *httpx.ResponseInfo) *http.Response {
+	var response = withHeaders()
+	response.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	response.Request = response.Request.WithContext(httpx.WithResponseInfo(response.Request.Context(), info))
+	return response
+}
+
+func TestExpectHeaderOrdering(t *testing.T) {
+	var response = withInfo(&httpx.ResponseInfo{HeaderOrder: []string{"Content-Type", "X-Request-Id", "Date", "Grpc-Status"}})
+
+	assert(t, ExpectHeaderOrdering("Content-Type", "Grpc-Status")(response) == nil, "must pass for headers in order")
+	assert(t, ExpectHeaderOrdering("content-type", "x-request-id", "date")(response) == nil, "must ignore case")
+	assert(t, ExpectHeaderOrdering("Grpc-Status", "Content-Type")(response) != nil, "must fail for headers out of order")
+	assert(t, ExpectHeaderOrdering("Content-Type", "Grpc-Message")(response) != nil, "must fail for missing header")
+	assert(t, ExpectHeaderOrdering("Content-Type")(withHeaders("Content-Type", "text/plain")) != nil, "must fail if order wasn't recorded")
+	assert(t, ExpectHeaderOrdering("Content-Type")(withInfo(&httpx.ResponseInfo{})) != nil, "must fail if only other info was recorded")
+}
+
+func TestExpectTraceParent(t *testing.T) {
+	var valid = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	assert(t, ExpectTraceParent()(withHeaders("traceparent", valid)) == nil, "must pass for valid header")
+	assert(t, ExpectTraceParent()(withHeaders()) != nil, "must fail for missing header")
+	assert(t, ExpectTraceParent()(withHeaders("traceparent", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01")) != nil,
+		"must fail for upper case hex")
+	assert(t, ExpectTraceParent()(withHeaders("traceparent", "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")) != nil,
+		"must fail for unknown version")
+	assert(t, ExpectTraceParent()(withHeaders("traceparent", "00-00000000000000000000000000000000-00f067aa0ba902b7-01")) != nil,
+		"must fail for all zero trace id")
+	assert(t, ExpectTraceParent()(withHeaders("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01")) != nil,
+		"must fail for all zero parent id")
+}
+
+func TestExpectSameTraceID(t *testing.T) {
+	var id = [16]byte{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+	var response = withHeaders("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	assert(t, ExpectSameTraceID(id)(response) == nil, "must pass for same trace id")
+	assert(t, ExpectSameTraceID([16]byte{1})(response) != nil, "must fail for different trace id")
+	assert(t, ExpectSameTraceID(id)(withHeaders()) != nil, "must fail for missing header")
+}
+
+func TestExpectNoHeaders(t *testing.T) {
+	var response = withHeaders("Server", "nginx", "X-Powered-By", "PHP", "X-Empty", "")
+
+	assert(t, ExpectHeaderAbsent("X-Request-Id")(response) == nil, "must pass for absent header")
+	assert(t, ExpectHeaderAbsent("server")(response) != nil, "must fail for present header, ignoring case")
+	assert(t, ExpectNoHeaders("X-Request-Id", "X-Debug")(response) == nil, "must pass if all headers are absent")
+	assert(t, ExpectNoHeaders("X-Empty")(response) != nil, "must fail for header with empty value")
+
+	var err = ExpectNoHeaders("Server", "X-Debug", "X-Powered-By")(response)
+	assert(t, err != nil && strings.Contains(err.Error(), "[Server, X-Powered-By]"), "must report all present headers, got: %v", err)
+}
+
+func TestExpectConnectionReuse(t *testing.T) {
+	var reused = func(b bool) *http.Response { return withInfo(&httpx.ResponseInfo{ConnectionReused: &b}) }
+	assert(t, ExpectConnectionReuse(true)(reused(true)) == nil, "must pass for reused connection")
+	assert(t, ExpectConnectionReuse(false)(reused(false)) == nil, "must pass for new connection")
+	assert(t, ExpectConnectionReuse(true)(reused(false)) != nil, "must fail if not reused")
+	assert(t, ExpectConnectionReuse(false)(withHeaders()) != nil, "must fail if reuse wasn't recorded")
+	assert(t, ExpectConnectionReuse(false)(withInfo(&httpx.ResponseInfo{})) != nil, "must fail if only other info was recorded")
+}
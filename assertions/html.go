@@ -0,0 +1,119 @@
+package assertions
+
+import (
+	"encoding/xml"
+	"fmt"
+	"go.riyazali.net/httpx"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ExpectHTMLInputValue returns an assertion that parses the html response body, finds the
+// <input> element with the given name and compares its value attribute to wantValue. For a <textarea>
+// element, its text content is compared instead, and for a <select> element, the value of its selected <option>
+// (or, like browsers do, of its first option if none is selected) is compared.
+//
+// The body is parsed using encoding/xml in non-strict mode which handles most well-formed html documents.
+func ExpectHTMLInputValue(inputName, wantValue string) httpx.Assertion {
+	return func(response *http.Response) (err error) {
+		defer checkClose(response.Body, &err)
+
+		var decoder = xml.NewDecoder(response.Body)
+		decoder.Strict = false
+		decoder.AutoClose = xml.HTMLAutoClose
+		decoder.Entity = xml.HTMLEntity
+
+		var names []string  // names of all the inputs found in the document
+		var selecting bool  // are we inside the <select> element we are looking for?
+		var textarea bool   // are we inside the <textarea> element we are looking for?
+		var option struct { // the <option> being read, inside the <select> element we are looking for
+			reading, selected, hasValue bool
+			value                       string
+		}
+		var first *string // value of the first option of the select
+		var text strings.Builder
+		for {
+			var token, err = decoder.Token()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return fmt.Errorf("html: failed to parse response body: %v", err)
+			}
+
+			switch tok := token.(type) {
+			case xml.StartElement:
+				var tag = strings.ToLower(tok.Name.Local)
+				var name, hasName = attr(tok, "name")
+				if (tag == "input" || tag == "select" || tag == "textarea") && hasName {
+					names = append(names, name)
+				}
+
+				if tag == "input" && hasName && name == inputName {
+					var value, _ = attr(tok, "value")
+					return compareInput(inputName, value, wantValue)
+				} else if tag == "textarea" && hasName && name == inputName {
+					textarea = true
+					text.Reset()
+				} else if tag == "select" && hasName && name == inputName {
+					selecting = true
+				} else if tag == "option" && selecting {
+					option.reading = true
+					option.value, option.hasValue = attr(tok, "value")
+					_, option.selected = attr(tok, "selected")
+					text.Reset()
+				}
+
+			case xml.CharData:
+				if textarea || option.reading {
+					text.Write(tok)
+				}
+
+			case xml.EndElement:
+				var tag = strings.ToLower(tok.Name.Local)
+				if tag == "textarea" && textarea {
+					// like browsers, ignore a newline right after the opening tag
+					var value = strings.TrimPrefix(strings.TrimPrefix(text.String(), "\r"), "\n")
+					return compareInput(inputName, value, wantValue)
+				} else if tag == "option" && option.reading {
+					option.reading = false
+					var value = option.value
+					if !option.hasValue {
+						value = strings.TrimSpace(text.String()) // value is the text content of the option
+					}
+					if option.selected {
+						return compareInput(inputName, value, wantValue)
+					}
+					if first == nil {
+						first = &value
+					}
+				} else if tag == "select" && selecting {
+					if first == nil {
+						return fmt.Errorf("html: select '%s' has no options", inputName)
+					}
+					return compareInput(inputName, *first, wantValue)
+				}
+			}
+		}
+
+		return fmt.Errorf("html: input '%s' not found, found inputs: [%s]", inputName, strings.Join(names, ", "))
+	}
+}
+
+// attr returns the value of the named attribute (matched case-insensitively) on the element
+func attr(el xml.StartElement, name string) (string, bool) {
+	for _, a := range el.Attr {
+		if strings.EqualFold(a.Name.Local, name) {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// compareInput compares the value of an html input with the expected value
+func compareInput(name, value, want string) error {
+	if value != want {
+		return fmt.Errorf("html: value of input '%s' (%s) not equal to expected value (%s)", name, value, want)
+	}
+	return nil
+}
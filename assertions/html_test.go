@@ -0,0 +1,59 @@
+package assertions_test
+
+import (
+	. "go.riyazali.net/httpx/assertions"
+	"strings"
+	"testing"
+)
+
+func TestExpectHTMLInputValue(t *testing.T) {
+	var body = `<!DOCTYPE html>
+<html>
+<body>
+  <form method="post">
+    <input type="text" name="username" value="alice &amp; bob">
+    <input type="checkbox" name="remember" checked>
+    <select name="country">
+      <option value="in">India</option>
+      <option value="us" selected>United States</option>
+    </select>
+    <select name="color">
+      <option>Red</option>
+      <option selected> Blue </option>
+    </select>
+    <select name="unselected"><option value="a">A</option><option value="b">B</option></select>
+    <select name="empty"></select>
+    <textarea name="bio">
+Hello &amp; welcome
+</textarea>
+    <br>
+  </form>
+</body>
+</html>`
+
+	t.Run("should compare input value", func(t *testing.T) {
+		assert(t, ExpectHTMLInputValue("username", "alice & bob")(withBody(body)) == nil, "must pass for correct value")
+		assert(t, ExpectHTMLInputValue("username", "alice")(withBody(body)) != nil, "must fail for incorrect value")
+		assert(t, ExpectHTMLInputValue("remember", "")(withBody(body)) == nil, "must treat missing value as empty")
+	})
+
+	t.Run("should compare selected option", func(t *testing.T) {
+		assert(t, ExpectHTMLInputValue("country", "us")(withBody(body)) == nil, "must use value of selected option")
+		assert(t, ExpectHTMLInputValue("country", "in")(withBody(body)) != nil, "must fail for option not selected")
+		assert(t, ExpectHTMLInputValue("color", "Blue")(withBody(body)) == nil, "must use text of selected option without value")
+		assert(t, ExpectHTMLInputValue("unselected", "a")(withBody(body)) == nil, "must use first option if none is selected")
+		assert(t, ExpectHTMLInputValue("unselected", "b")(withBody(body)) != nil, "must fail for other option if none is selected")
+		assert(t, ExpectHTMLInputValue("empty", "")(withBody(body)) != nil, "must fail if select has no options")
+	})
+
+	t.Run("should compare textarea content", func(t *testing.T) {
+		assert(t, ExpectHTMLInputValue("bio", "Hello & welcome\n")(withBody(body)) == nil, "must use text of textarea")
+		assert(t, ExpectHTMLInputValue("bio", "")(withBody(body)) != nil, "must fail for different text")
+	})
+
+	t.Run("should list inputs if not found", func(t *testing.T) {
+		var err = ExpectHTMLInputValue("password", "")(withBody(body))
+		assert(t, err != nil, "must fail if input is not found")
+		assert(t, err != nil && strings.Contains(err.Error(), "username, remember, country"), "must list found inputs")
+	})
+}
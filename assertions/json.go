@@ -0,0 +1,364 @@
+package assertions
+
+import (
+	"encoding/json"
+	"fmt"
+	"go.riyazali.net/httpx"
+	. "go.riyazali.net/httpx/helpers"
+	"math"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimestampOption defines a function that performs additional checks on a timestamp parsed by ExpectJSONTimestamp
+type TimestampOption func(time.Time) error
+
+// WithinLast returns a TimestampOption that fails if the timestamp is older than d
+func WithinLast(d time.Duration) TimestampOption {
+	return func(ts time.Time) error {
+		if age := time.Since(ts); age > d {
+			return fmt.Errorf("timestamp (%s) is older than %s", ts.Format(time.RFC3339), d)
+		}
+		return nil
+	}
+}
+
+// Before returns a TimestampOption that fails if the timestamp is not before t
+func Before(t time.Time) TimestampOption {
+	return func(ts time.Time) error {
+		if !ts.Before(t) {
+			return fmt.Errorf("timestamp (%s) is not before %s", ts.Format(time.RFC3339), t.Format(time.RFC3339))
+		}
+		return nil
+	}
+}
+
+// After returns a TimestampOption that fails if the timestamp is not after t
+func After(t time.Time) TimestampOption {
+	return func(ts time.Time) error {
+		if !ts.After(t) {
+			return fmt.Errorf("timestamp (%s) is not after %s", ts.Format(time.RFC3339), t.Format(time.RFC3339))
+		}
+		return nil
+	}
+}
+
+// ExpectJSONTimestamp returns an assertion that resolves the given path in the json response body
+// and checks that the value is a string parseable as an RFC 3339 timestamp. Any additional checks
+// provided using opts are then applied on the parsed value.
+//
+//    ExpectJSONTimestamp("data.created_at", WithinLast(time.Minute))
+//
+// A path is a dot-separated list of object keys and (zero-based) array indices, such as "data.items.0.id".
+func ExpectJSONTimestamp(path string, opts ...TimestampOption) httpx.Assertion {
+	return withJsonPath(path, func(v interface{}) error {
+		var str, ok = v.(string)
+		if !ok {
+			return fmt.Errorf("path '%s': value (%v) is not a string", path, v)
+		}
+
+		var ts, err = time.Parse(time.RFC3339, str)
+		if err != nil {
+			return fmt.Errorf("path '%s': value (%s) is not a valid timestamp: %v", path, str, err)
+		}
+
+		for _, opt := range opts {
+			if err := opt(ts); err != nil {
+				return fmt.Errorf("path '%s': %v", path, err)
+			}
+		}
+		return nil
+	})
+}
+
+// ExpectJSONKeyExists returns an assertion that checks that the given path resolves in the json response body,
+// irrespective of the value found there (which could even be null).
+func ExpectJSONKeyExists(path string) httpx.Assertion {
+	return withJsonPath(path, func(interface{}) error { return nil })
+}
+
+// ExpectJSONStringField returns an assertion that checks that the value at path, in the json response body,
+// is a string equal to want. See ExpectJSONTimestamp(...) for path syntax.
+func ExpectJSONStringField(path, want string) httpx.Assertion {
+	return withJsonPath(path, func(v interface{}) error {
+		var str, ok = v.(string)
+		if !ok {
+			return fmt.Errorf("path '%s': value (%v) is not a string", path, v)
+		}
+		return AssertThat(str == want, "path '%s': value (%s) not equal to expected (%s)", path, str, want)
+	})
+}
+
+// ExpectJSONBoolField returns an assertion that checks that the value at path, in the json response body, is a boolean equal to want.
+func ExpectJSONBoolField(path string, want bool) httpx.Assertion {
+	return withJsonPath(path, func(v interface{}) error {
+		var b, ok = v.(bool)
+		if !ok {
+			return fmt.Errorf("path '%s': value (%v) is not a boolean", path, v)
+		}
+		return AssertThat(b == want, "path '%s': value (%t) not equal to expected (%t)", path, b, want)
+	})
+}
+
+// ExpectJSONIntField returns an assertion that checks that the value at path, in the json response body, is an integer equal to want.
+func ExpectJSONIntField(path string, want int64) httpx.Assertion {
+	return withJsonPath(path, func(v interface{}) error {
+		var n, ok = number(v)
+		var i, err = n.Int64()
+		if !ok || err != nil {
+			return fmt.Errorf("path '%s': value (%v) is not an integer", path, v)
+		}
+		return AssertThat(i == want, "path '%s': value (%d) not equal to expected (%d)", path, i, want)
+	})
+}
+
+// ExpectJSONFloat64Field returns an assertion that checks that the value at path, in the json response body,
+// is a number within epsilon of want.
+func ExpectJSONFloat64Field(path string, want float64, epsilon float64) httpx.Assertion {
+	return withJsonPath(path, func(v interface{}) error {
+		var n, err = float(v)
+		if err != nil {
+			return fmt.Errorf("path '%s': value (%v) is not a number", path, v)
+		}
+		return AssertThat(math.Abs(n-want) <= epsilon, "path '%s': value (%v) not within %v of expected (%v)", path, n, epsilon, want)
+	})
+}
+
+// ExpectJSONArray returns an assertion that resolves the given path in the json response body as an array and invokes
+// each of the elementAssertions on every element of it, in order. Values are decoded as with encoding/json into an interface{},
+// except that numbers are decoded as json.Number.
+//
+//    ExpectJSONArray("data.items", func(i int, elem interface{}) error {
+//        return AssertThat(elem.(map[string]interface{})["id"] != nil, "item %d has no id", i)
+//    })
+func ExpectJSONArray(path string, elementAssertions ...func(index int, elem interface{}) error) httpx.Assertion {
+	return withJsonPath(path, func(v interface{}) error {
+		var array, ok = v.([]interface{})
+		if !ok {
+			return fmt.Errorf("path '%s': value is not an array", path)
+		}
+
+		var errs []error
+		for i, elem := range array {
+			for _, fn := range elementAssertions {
+				if err := fn(i, elem); err != nil {
+					errs = append(errs, fmt.Errorf("path '%s': element %d: %v", path, i, err))
+				}
+			}
+		}
+		return Multiple(errs...)
+	})
+}
+
+// ExpectJSONArrayAll returns an assertion that resolves the given path in the json response body as
+// an array and fails with failMsg if pred returns false for any of the elements in it.
+func ExpectJSONArrayAll(path string, pred func(interface{}) bool, failMsg string) httpx.Assertion {
+	return ExpectJSONArray(path, func(_ int, elem interface{}) error {
+		return AssertThat(pred(elem), "%s", failMsg)
+	})
+}
+
+// ExpectJSONResponseEnvelope returns an assertion for APIs that wrap all responses in a standard envelope, like,
+//    {"success": true, "data": {...}, "error": "..."}
+// It checks that success matches wantSuccess and, if dataPath is non-empty, that dataPath resolves
+// within the data object (see ExpectJSONTimestamp for path syntax).
+//
+// For envelopes with a different structure, use BodyJson(...) with a site-specific struct instead, such as,
+//    BodyJson(func(e struct{ Ok bool `json:"ok"`; Result json.RawMessage `json:"result"` }) error {
+//        return AssertThat(e.Ok, "request failed")
+//    })
+func ExpectJSONResponseEnvelope(wantSuccess bool, dataPath string) httpx.Assertion {
+	return withJsonPath("", func(v interface{}) error {
+		var envelope, ok = v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("envelope: response body is not an object")
+		}
+
+		var success, isBool = envelope["success"].(bool)
+		if !isBool {
+			return fmt.Errorf("envelope: 'success' is missing or not a boolean")
+		}
+		if success != wantSuccess {
+			return fmt.Errorf("envelope: success (%t) not equal to expected (%t), error: %v", success, wantSuccess, envelope["error"])
+		}
+
+		if dataPath != "" {
+			var data, ok = envelope["data"]
+			if !ok {
+				return fmt.Errorf("envelope: 'data' is missing")
+			}
+			if _, err := resolve(data, dataPath); err != nil {
+				return fmt.Errorf("envelope: data: %v", err)
+			}
+		}
+		return nil
+	})
+}
+
+// DefaultResponseTimePath is the path used by ExpectJSONResponseTime(...) if none is given
+const DefaultResponseTimePath = "_meta.response_time_ms"
+
+// ExpectJSONResponseTime returns an assertion for APIs that report the time taken to serve a request (in milliseconds)
+// in the json response body. It resolves the given path (or DefaultResponseTimePath, if empty), checks that the value
+// is a number and fails if it exceeds maxMs. Unlike client-side latency checks, this only measures the server's work.
+func ExpectJSONResponseTime(path string, maxMs float64) httpx.Assertion {
+	if path == "" {
+		path = DefaultResponseTimePath
+	}
+	return withJsonPath(path, func(v interface{}) error {
+		var ms, err = float(v)
+		if err != nil {
+			return fmt.Errorf("path '%s': value (%v) is not a number", path, v)
+		}
+		if ms > maxMs {
+			return fmt.Errorf("path '%s': response time (%vms) exceeds %vms", path, ms, maxMs)
+		}
+		return nil
+	})
+}
+
+// ProblemDetailOption defines a function that performs additional checks on an RFC 7807 problem details
+// object (decoded into a map) parsed by ExpectJSONError
+type ProblemDetailOption func(problem map[string]interface{}) error
+
+// WithTitle returns a ProblemDetailOption that checks that problem's title is equal to the given value
+func WithTitle(title string) ProblemDetailOption { return problemMember("title", title) }
+
+// WithDetail returns a ProblemDetailOption that checks that problem's detail is equal to the given value
+func WithDetail(detail string) ProblemDetailOption { return problemMember("detail", detail) }
+
+// WithType returns a ProblemDetailOption that checks that problem's type is equal to the given uri.
+// As per the specification, a problem without a type is treated as having the type "about:blank".
+func WithType(uri string) ProblemDetailOption {
+	var check = problemMember("type", uri)
+	return func(problem map[string]interface{}) error {
+		if _, ok := problem["type"]; !ok {
+			return check(map[string]interface{}{"type": "about:blank"})
+		}
+		return check(problem)
+	}
+}
+
+// problemMember returns a ProblemDetailOption that checks that the given member of the problem is equal to want
+func problemMember(name, want string) ProblemDetailOption {
+	return func(problem map[string]interface{}) error {
+		if got, ok := problem[name].(string); !ok || got != want {
+			return fmt.Errorf("%s (%v) not equal to expected (%s)", name, problem[name], want)
+		}
+		return nil
+	}
+}
+
+// ExpectJSONError returns an assertion for APIs that report errors using RFC 7807 Problem Details, like,
+//    {"type": "https://example.com/probs/out-of-credit", "title": "You do not have enough credit.", "status": 403, "detail": "..."}
+// It checks that the response has the given status and an application/problem+json Content-Type, and that the status
+// member (if present) matches it too. Any additional checks provided using opts are then applied on the problem.
+//
+//    ExpectJSONError(http.StatusForbidden, WithTitle("You do not have enough credit."))
+func ExpectJSONError(wantStatus int, opts ...ProblemDetailOption) httpx.Assertion {
+	var assertion = withJsonPath("", func(v interface{}) error {
+		var problem, ok = v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("problem: response body is not an object")
+		}
+
+		if status, present := problem["status"]; present {
+			if n, err := float(status); err != nil || n != float64(wantStatus) {
+				return fmt.Errorf("problem: status (%v) not equal to expected (%d)", status, wantStatus)
+			}
+		}
+
+		var errs []error
+		for _, opt := range opts {
+			if err := opt(problem); err != nil {
+				errs = append(errs, fmt.Errorf("problem: %v", err))
+			}
+		}
+		return Multiple(errs...)
+	})
+
+	return func(response *http.Response) error {
+		if response.StatusCode != wantStatus {
+			return fmt.Errorf("problem: response status (%d) not equal to expected (%d)", response.StatusCode, wantStatus)
+		}
+		if mt, _, _ := mime.ParseMediaType(response.Header.Get("Content-Type")); mt != "application/problem+json" {
+			return fmt.Errorf("problem: content type (%s) is not application/problem+json", response.Header.Get("Content-Type"))
+		}
+		return assertion(response)
+	}
+}
+
+// number returns the json number v, as decoded by withJsonPath(...)
+func number(v interface{}) (json.Number, bool) {
+	var n, ok = v.(json.Number)
+	return n, ok
+}
+
+// float returns the json number v as a float64, failing if v is not a number
+func float(v interface{}) (float64, error) {
+	var n, ok = number(v)
+	if !ok {
+		return 0, fmt.Errorf("value (%v) is not a number", v)
+	}
+	return n.Float64()
+}
+
+// withJsonPath returns an assertion that decodes the json response body, resolves the given path
+// and invokes the callback with the value found there.
+func withJsonPath(path string, cb func(interface{}) error) httpx.Assertion {
+	return func(response *http.Response) (err error) {
+		defer checkClose(response.Body, &err)
+
+		var body interface{}
+		var decoder = json.NewDecoder(response.Body)
+		decoder.UseNumber()
+		if err := decoder.Decode(&body); err != nil {
+			return fmt.Errorf("json: failed to decode response body: %v", err)
+		}
+
+		var v interface{}
+		if v, err = resolve(body, path); err != nil {
+			return fmt.Errorf("json: %v", err)
+		}
+
+		if err := cb(v); err != nil {
+			return fmt.Errorf("json: %v", err)
+		}
+		return nil
+	}
+}
+
+// resolve walks the given dot-separated path through the decoded json value and returns the value found at the end of it
+func resolve(v interface{}, path string) (interface{}, error) {
+	if path == "" {
+		return v, nil
+	}
+
+	for _, key := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			var ok bool
+			if v, ok = node[key]; !ok {
+				var keys = make([]string, 0, len(node))
+				for k := range node {
+					keys = append(keys, k)
+				}
+				sort.Strings(keys)
+				return nil, fmt.Errorf("path '%s': key '%s' not found, available keys: [%s]", path, key, strings.Join(keys, ", "))
+			}
+		case []interface{}:
+			var i, err = strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, fmt.Errorf("path '%s': invalid index '%s' for array of length %d", path, key, len(node))
+			}
+			v = node[i]
+		default:
+			return nil, fmt.Errorf("path '%s': cannot resolve '%s' on a non-container value", path, key)
+		}
+	}
+	return v, nil
+}
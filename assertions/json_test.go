@@ -0,0 +1,190 @@
+package assertions_test
+
+import (
+	"encoding/json"
+	"errors"
+	. "go.riyazali.net/httpx/assertions"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// helper function to build a response with the given body
+func withBody(body string) *http.Response {
+	var writer = httptest.NewRecorder()
+	_, _ = io.WriteString(writer, body)
+	return writer.Result()
+}
+
+func TestExpectJSONTimestamp(t *testing.T) {
+	var now = time.Now().UTC()
+	var body = `{"data": {"items": [{"created_at": "` + now.Format(time.RFC3339Nano) + `", "id": 1}]}}`
+
+	t.Run("should pass for valid timestamp", func(t *testing.T) {
+		assert(t, ExpectJSONTimestamp("data.items.0.created_at")(withBody(body)) == nil, "must pass for valid timestamp")
+	})
+
+	t.Run("should apply options", func(t *testing.T) {
+		assert(t, ExpectJSONTimestamp("data.items.0.created_at", WithinLast(time.Minute))(withBody(body)) == nil,
+			"must pass if timestamp is recent")
+		assert(t, ExpectJSONTimestamp("data.items.0.created_at", After(now.Add(-time.Hour)), Before(now.Add(time.Hour)))(withBody(body)) == nil,
+			"must pass if timestamp is in range")
+		assert(t, ExpectJSONTimestamp("data.items.0.created_at", Before(now.Add(-time.Hour)))(withBody(body)) != nil,
+			"must fail if timestamp is not before given time")
+		assert(t, ExpectJSONTimestamp("data.items.0.created_at", After(now.Add(time.Hour)))(withBody(body)) != nil,
+			"must fail if timestamp is not after given time")
+	})
+
+	t.Run("should fail for old timestamp", func(t *testing.T) {
+		var old = `{"created_at": "2006-01-02T15:04:05Z"}`
+		assert(t, ExpectJSONTimestamp("created_at", WithinLast(time.Hour))(withBody(old)) != nil, "must fail for old timestamp")
+	})
+
+	t.Run("should fail for invalid values", func(t *testing.T) {
+		assert(t, ExpectJSONTimestamp("data.items.0.id")(withBody(body)) != nil, "must fail for non-string value")
+		assert(t, ExpectJSONTimestamp("a")(withBody(`{"a": "yesterday"}`)) != nil, "must fail for unparseable value")
+		assert(t, ExpectJSONTimestamp("data.items.1.created_at")(withBody(body)) != nil, "must fail for out-of-range index")
+		assert(t, ExpectJSONTimestamp("data.missing")(withBody(body)) != nil, "must fail for missing key")
+		assert(t, ExpectJSONTimestamp("a")(withBody(`not json`)) != nil, "must fail for invalid json")
+	})
+}
+
+func TestExpectJSONResponseEnvelope(t *testing.T) {
+	var ok = `{"success": true, "data": {"user": {"id": 1}}}`
+	var failed = `{"success": false, "error": "not allowed"}`
+
+	assert(t, ExpectJSONResponseEnvelope(true, "")(withBody(ok)) == nil, "must pass for successful envelope")
+	assert(t, ExpectJSONResponseEnvelope(true, "user.id")(withBody(ok)) == nil, "must pass if data path resolves")
+	assert(t, ExpectJSONResponseEnvelope(true, "user.name")(withBody(ok)) != nil, "must fail if data path doesn't resolve")
+	assert(t, ExpectJSONResponseEnvelope(false, "")(withBody(failed)) == nil, "must pass for failed envelope")
+	assert(t, ExpectJSONResponseEnvelope(true, "")(withBody(failed)) != nil, "must fail if success doesn't match")
+	assert(t, ExpectJSONResponseEnvelope(false, "user")(withBody(failed)) != nil, "must fail if data is missing")
+	assert(t, ExpectJSONResponseEnvelope(true, "")(withBody(`{"ok": true}`)) != nil, "must fail if success is missing")
+	assert(t, ExpectJSONResponseEnvelope(true, "")(withBody(`[]`)) != nil, "must fail if body is not an object")
+}
+
+func TestExpectJSONKeyExists(t *testing.T) {
+	var body = `{"data": {"id": 1, "name": null, "tags": [{"a": false}]}}`
+	assert(t, ExpectJSONKeyExists("data.id")(withBody(body)) == nil, "must pass for existing key")
+	assert(t, ExpectJSONKeyExists("data.name")(withBody(body)) == nil, "must pass for null value")
+	assert(t, ExpectJSONKeyExists("data.tags.0.a")(withBody(body)) == nil, "must pass for nested key")
+
+	var err = ExpectJSONKeyExists("data.nmae")(withBody(body))
+	assert(t, err != nil, "must fail for missing key")
+	assert(t, err != nil && strings.Contains(err.Error(), "[id, name, tags]"), "must list available keys")
+}
+
+func TestExpectJSONArray(t *testing.T) {
+	var body = `{"items": [{"id": 1, "price": 10}, {"id": 2, "price": 20}], "count": 2}`
+
+	t.Run("should invoke assertions on every element", func(t *testing.T) {
+		var indices []int
+		var err = ExpectJSONArray("items", func(i int, elem interface{}) error {
+			indices = append(indices, i)
+			return nil
+		})(withBody(body))
+		assert(t, err == nil, "must pass if all assertions pass")
+		assert(t, len(indices) == 2 && indices[0] == 0 && indices[1] == 1, "must invoke assertions in order")
+	})
+
+	t.Run("should fail if any element fails", func(t *testing.T) {
+		var err = ExpectJSONArray("items", func(i int, elem interface{}) error {
+			if elem.(map[string]interface{})["id"] == json.Number("2") {
+				return errors.New("bad element")
+			}
+			return nil
+		})(withBody(body))
+		assert(t, err != nil && strings.Contains(err.Error(), "element 1"), "must report failing element")
+	})
+
+	t.Run("should fail if value is not an array", func(t *testing.T) {
+		assert(t, ExpectJSONArray("count")(withBody(body)) != nil, "must fail for non-array value")
+	})
+
+	t.Run("should decode numbers as json.Number", func(t *testing.T) {
+		var id interface{}
+		_ = ExpectJSONArray("ids", func(_ int, elem interface{}) error { id = elem; return nil })(withBody(`{"ids": [9007199254740993]}`))
+		assert(t, id == json.Number("9007199254740993"), "must retain precision of numbers, got: %v", id)
+	})
+
+	t.Run("should check predicate for all elements", func(t *testing.T) {
+		var price = func(elem interface{}) float64 {
+			var f, _ = elem.(map[string]interface{})["price"].(json.Number).Float64()
+			return f
+		}
+		var positive = func(elem interface{}) bool { return price(elem) > 0 }
+		var cheap = func(elem interface{}) bool { return price(elem) < 15 }
+		assert(t, ExpectJSONArrayAll("items", positive, "price must be positive")(withBody(body)) == nil, "must pass if predicate holds")
+
+		var err = ExpectJSONArrayAll("items", cheap, "price must be less than 15")(withBody(body))
+		assert(t, err != nil && strings.Contains(err.Error(), "price must be less than 15"), "must fail with message")
+	})
+}
+
+func TestExpectJSONError(t *testing.T) {
+	var problem = func(status int, contentType, body string) *http.Response {
+		var response = withBody(body)
+		response.StatusCode = status
+		response.Header.Set("Content-Type", contentType)
+		return response
+	}
+
+	var body = `{"type": "https://example.com/probs/out-of-credit", "title": "Not enough credit", "status": 403, "detail": "balance is 30"}`
+	var valid = func() *http.Response {
+		return problem(http.StatusForbidden, "application/problem+json; charset=utf-8", body)
+	}
+
+	assert(t, ExpectJSONError(http.StatusForbidden)(valid()) == nil, "must pass for valid problem")
+	assert(t, ExpectJSONError(http.StatusForbidden, WithTitle("Not enough credit"), WithDetail("balance is 30"),
+		WithType("https://example.com/probs/out-of-credit"))(valid()) == nil, "must pass if all options pass")
+	assert(t, ExpectJSONError(http.StatusForbidden, WithTitle("Forbidden"))(valid()) != nil, "must fail for different title")
+	assert(t, ExpectJSONError(http.StatusForbidden, WithDetail("balance is 0"))(valid()) != nil, "must fail for different detail")
+	assert(t, ExpectJSONError(http.StatusForbidden, WithType("about:blank"))(valid()) != nil, "must fail for different type")
+	assert(t, ExpectJSONError(http.StatusNotFound)(valid()) != nil, "must fail for different response status")
+
+	assert(t, ExpectJSONError(http.StatusForbidden)(problem(http.StatusForbidden, "application/json", body)) != nil,
+		"must fail for different content type")
+	assert(t, ExpectJSONError(http.StatusForbidden)(problem(http.StatusForbidden, "application/problem+json", `{"status": 400}`)) != nil,
+		"must fail if status member doesn't match")
+	assert(t, ExpectJSONError(http.StatusForbidden, WithType("about:blank"))(problem(http.StatusForbidden, "application/problem+json", `{}`)) == nil,
+		"must default type to about:blank")
+	assert(t, ExpectJSONError(http.StatusForbidden)(problem(http.StatusForbidden, "application/problem+json", `[]`)) != nil,
+		"must fail if body is not an object")
+}
+
+func TestExpectJSONResponseTime(t *testing.T) {
+	var body = `{"data": {}, "_meta": {"response_time_ms": 42.5}, "timing": {"server": 120}}`
+
+	assert(t, ExpectJSONResponseTime("", 50)(withBody(body)) == nil, "must use default path")
+	assert(t, ExpectJSONResponseTime("", 40)(withBody(body)) != nil, "must fail if response time exceeds max")
+	assert(t, ExpectJSONResponseTime("timing.server", 200)(withBody(body)) == nil, "must use given path")
+	assert(t, ExpectJSONResponseTime("timing.server", 100)(withBody(body)) != nil, "must fail if value at path exceeds max")
+	assert(t, ExpectJSONResponseTime("", 50)(withBody(`{"_meta": {"response_time_ms": "fast"}}`)) != nil, "must fail for non-number")
+	assert(t, ExpectJSONResponseTime("", 50)(withBody(`{"data": {}}`)) != nil, "must fail for missing path")
+}
+
+func TestExpectJSONTypedFields(t *testing.T) {
+	var body = `{"user": {"name": "john", "active": true, "age": 42, "score": 9.75}}`
+
+	assert(t, ExpectJSONStringField("user.name", "john")(withBody(body)) == nil, "must pass for equal string")
+	assert(t, ExpectJSONStringField("user.name", "jane")(withBody(body)) != nil, "must fail for different string")
+	assert(t, ExpectJSONStringField("user.age", "42")(withBody(body)) != nil, "must fail for non-string")
+
+	assert(t, ExpectJSONBoolField("user.active", true)(withBody(body)) == nil, "must pass for equal bool")
+	assert(t, ExpectJSONBoolField("user.active", false)(withBody(body)) != nil, "must fail for different bool")
+	assert(t, ExpectJSONBoolField("user.name", true)(withBody(body)) != nil, "must fail for non-bool")
+
+	assert(t, ExpectJSONIntField("user.age", 42)(withBody(body)) == nil, "must pass for equal int")
+	assert(t, ExpectJSONIntField("user.age", 41)(withBody(body)) != nil, "must fail for different int")
+	assert(t, ExpectJSONIntField("user.score", 9)(withBody(body)) != nil, "must fail for non-integer number")
+	assert(t, ExpectJSONIntField("id", 9007199254740993)(withBody(`{"id": 9007199254740993}`)) == nil, "must compare large integers precisely")
+	assert(t, ExpectJSONIntField("id", 9007199254740992)(withBody(`{"id": 9007199254740993}`)) != nil, "must fail for nearby large integer")
+
+	assert(t, ExpectJSONFloat64Field("user.score", 9.7, 0.1)(withBody(body)) == nil, "must pass within epsilon")
+	assert(t, ExpectJSONFloat64Field("user.score", 9.7, 0.01)(withBody(body)) != nil, "must fail outside epsilon")
+	assert(t, ExpectJSONFloat64Field("user.name", 0, 1)(withBody(body)) != nil, "must fail for non-number")
+	assert(t, ExpectJSONFloat64Field("user.missing", 0, 1)(withBody(body)) != nil, "must fail for missing path")
+}
@@ -0,0 +1,42 @@
+//go:build go1.21
+// +build go1.21
+
+package assertions
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"go.riyazali.net/httpx"
+	"net/http"
+	"strings"
+)
+
+// ExpectBodyParseAs returns an assertion that decodes the response body into dest, using the given format
+// (either "json" or "xml"), and fails only if the body couldn't be decoded. The caller can then inspect
+// the typed value in dest, instead of type-asserting on interface{} values, such as,
+//
+//    var user User
+//    MakeRequest(Get("/users/1")).ExpectIt(t, ExpectBodyParseAs(&user, "json"))
+//    // use user.Name ...
+//
+// Other formats (like "yaml") need third-party decoders and aren't supported; use BodyBytes(...) with one instead.
+func ExpectBodyParseAs[T any](dest *T, format string) httpx.Assertion {
+	var decode func(*http.Response) error
+	switch strings.ToLower(format) {
+	case "json":
+		decode = func(response *http.Response) error { return json.NewDecoder(response.Body).Decode(dest) }
+	case "xml":
+		decode = func(response *http.Response) error { return xml.NewDecoder(response.Body).Decode(dest) }
+	default:
+		return failed(fmt.Errorf("parse: unsupported format '%s'", format))
+	}
+
+	return func(response *http.Response) (err error) {
+		defer checkClose(response.Body, &err)
+		if err := decode(response); err != nil {
+			return fmt.Errorf("parse: failed to decode response body as %s: %v", format, err)
+		}
+		return nil
+	}
+}
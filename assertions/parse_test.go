@@ -0,0 +1,39 @@
+//go:build go1.21
+// +build go1.21
+
+package assertions_test
+
+import (
+	. "go.riyazali.net/httpx/assertions"
+	"testing"
+)
+
+func TestExpectBodyParseAs(t *testing.T) {
+	type user struct {
+		ID   int    `json:"id" xml:"id"`
+		Name string `json:"name" xml:"name"`
+	}
+
+	t.Run("should decode json", func(t *testing.T) {
+		var u user
+		assert(t, ExpectBodyParseAs(&u, "json")(withBody(`{"id": 1, "name": "john"}`)) == nil, "must decode json body")
+		assert(t, u.ID == 1 && u.Name == "john", "must populate destination")
+	})
+
+	t.Run("should decode xml", func(t *testing.T) {
+		var u user
+		assert(t, ExpectBodyParseAs(&u, "XML")(withBody(`<user><id>2</id><name>jane</name></user>`)) == nil, "must decode xml body")
+		assert(t, u.ID == 2 && u.Name == "jane", "must populate destination")
+	})
+
+	t.Run("should fail for invalid body", func(t *testing.T) {
+		var u user
+		assert(t, ExpectBodyParseAs(&u, "json")(withBody(`{"id": "one"}`)) != nil, "must fail for mismatched type")
+		assert(t, ExpectBodyParseAs(&u, "xml")(withBody(`not xml`)) != nil, "must fail for malformed body")
+	})
+
+	t.Run("should fail for unsupported format", func(t *testing.T) {
+		var u user
+		assert(t, ExpectBodyParseAs(&u, "yaml")(withBody("id: 1")) != nil, "must fail for unsupported format")
+	})
+}
@@ -0,0 +1,105 @@
+package assertions
+
+import (
+	"fmt"
+	"go.riyazali.net/httpx"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ExpectRedirectChain returns an assertion that checks that the request followed the given chain of redirects. The urls
+// are all the urls requested, in order, starting with the original one and ending with the one that returned the response.
+// A url without a scheme and host (like "/login?next=%2F") only matches the path and query of the requested url.
+//
+//    MakeRequest(Get(server.URL + "/s/abc")).ExpectIt(t, ExpectRedirectChain("/s/abc", "/landing", "https://example.com/"))
+//
+// The chain is reconstructed from the requests linked to the response (see http.Request.Response) by http.Client
+// and so, this only works with ExecFn that make actual http calls (and not with in-memory handlers).
+// Use executors.WithMaxRedirects(...) to control the maximum number of redirects the client follows.
+func ExpectRedirectChain(urls ...string) httpx.Assertion {
+	return func(response *http.Response) error {
+		if response.Request == nil {
+			return fmt.Errorf("redirect: response has no request associated with it")
+		}
+
+		// walk back from the final request to the original one
+		var chain []*url.URL
+		for r := response.Request; r != nil; {
+			chain = append([]*url.URL{r.URL}, chain...)
+			if r.Response == nil {
+				break
+			}
+			r = r.Response.Request
+		}
+
+		var got = make([]string, len(chain))
+		for i, u := range chain {
+			got[i] = u.String()
+		}
+		if len(chain) != len(urls) {
+			return fmt.Errorf("redirect: chain (%s) has %d urls, expected %d", strings.Join(got, " -> "), len(chain), len(urls))
+		}
+
+		for i, want := range urls {
+			if !matchUrl(chain[i], want) {
+				return fmt.Errorf("redirect: url #%d (%s) not equal to expected (%s) in chain (%s)", i, got[i], want, strings.Join(got, " -> "))
+			}
+		}
+		return nil
+	}
+}
+
+// matchUrl reports whether u matches want, comparing only path and query if want is relative
+func matchUrl(u *url.URL, want string) bool {
+	var w, err = url.Parse(want)
+	if err != nil {
+		return false
+	}
+	if w.Scheme == "" && w.Host == "" {
+		return u.EscapedPath() == w.EscapedPath() && u.RawQuery == w.RawQuery
+	}
+	return u.String() == w.String()
+}
+
+// ExpectLocationHeader returns an assertion that checks that the response is a redirect (3xx) with its Location header
+// set exactly to wantURL. Unlike ExpectRedirectChain(...), it checks the redirect target without following it, and so,
+// is meant to be used with in-memory handlers or a client configured with executors.WithNoRedirect().
+func ExpectLocationHeader(wantURL string) httpx.Assertion {
+	return withLocation(func(location string) error {
+		if location != wantURL {
+			return fmt.Errorf("redirect: location (%s) not equal to expected (%s)", location, wantURL)
+		}
+		return nil
+	})
+}
+
+// ExpectLocationPath returns an assertion that checks that the response is a redirect (3xx) with its Location header
+// pointing to wantPath, ignoring other components (like the host or query) of the url. See ExpectLocationHeader(...)
+func ExpectLocationPath(wantPath string) httpx.Assertion {
+	return withLocation(func(location string) error {
+		var u, err = url.Parse(location)
+		if err != nil {
+			return fmt.Errorf("redirect: location (%s) is not a valid url: %v", location, err)
+		}
+		if u.Path != wantPath {
+			return fmt.Errorf("redirect: location path (%s) not equal to expected (%s)", u.Path, wantPath)
+		}
+		return nil
+	})
+}
+
+// withLocation returns an assertion that checks that the response is a redirect with a Location header and invokes
+// the callback with the header's value
+func withLocation(cb func(location string) error) httpx.Assertion {
+	return func(response *http.Response) error {
+		if response.StatusCode < 300 || response.StatusCode > 399 {
+			return fmt.Errorf("redirect: returned status (%d) is not a redirect", response.StatusCode)
+		}
+		var location = response.Header.Get("Location")
+		if location == "" {
+			return fmt.Errorf("redirect: Location header not found in response")
+		}
+		return cb(location)
+	}
+}
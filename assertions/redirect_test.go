@@ -0,0 +1,60 @@
+package assertions_test
+
+import (
+	. "go.riyazali.net/httpx/assertions"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestExpectRedirectChain(t *testing.T) {
+	var mux = http.NewServeMux()
+	mux.Handle("/s/abc", http.RedirectHandler("/login?next=%2Fhome", http.StatusFound))
+	mux.Handle("/login", http.RedirectHandler("/home", http.StatusSeeOther))
+	mux.HandleFunc("/home", func(http.ResponseWriter, *http.Request) {})
+	var server = httptest.NewServer(mux)
+	defer server.Close()
+
+	var response, err = http.Get(server.URL + "/s/abc")
+	if err != nil {
+		t.Fatalf("request must succeed: %v", err)
+	}
+	defer response.Body.Close()
+
+	assert(t, ExpectRedirectChain("/s/abc", "/login?next=%2Fhome", "/home")(response) == nil, "must pass for same chain")
+	assert(t, ExpectRedirectChain(server.URL+"/s/abc", "/login?next=%2Fhome", server.URL+"/home")(response) == nil,
+		"must match absolute urls")
+	assert(t, ExpectRedirectChain("/s/abc", "/login", "/home")(response) != nil, "must fail for different query")
+	assert(t, ExpectRedirectChain("/s/abc", "/home")(response) != nil, "must fail for different length")
+	assert(t, ExpectRedirectChain("http://example.com/s/abc", "/login?next=%2Fhome", "/home")(response) != nil,
+		"must fail for different host")
+
+	t.Run("should work without redirects", func(t *testing.T) {
+		var response, _ = http.Get(server.URL + "/home")
+		defer response.Body.Close()
+		assert(t, ExpectRedirectChain("/home")(response) == nil, "must pass for single url")
+	})
+
+	t.Run("should fail without request", func(t *testing.T) {
+		assert(t, ExpectRedirectChain("/home")(httptest.NewRecorder().Result()) != nil, "must fail without request")
+	})
+}
+
+func TestExpectLocationHeader(t *testing.T) {
+	var redirect = func(status int, location string) *http.Response {
+		var resp = withHeaders("Location", location)
+		resp.StatusCode = status
+		return resp
+	}
+
+	var response = redirect(http.StatusFound, "https://example.com/login?next=%2Fhome")
+	assert(t, ExpectLocationHeader("https://example.com/login?next=%2Fhome")(response) == nil, "must pass for same location")
+	assert(t, ExpectLocationHeader("https://example.com/login")(response) != nil, "must fail for different location")
+	assert(t, ExpectLocationPath("/login")(response) == nil, "must compare only path")
+	assert(t, ExpectLocationPath("/home")(response) != nil, "must fail for different path")
+	assert(t, ExpectLocationPath("/login")(redirect(http.StatusMovedPermanently, "/login")) == nil, "must work with relative location")
+
+	assert(t, ExpectLocationHeader("/login")(redirect(http.StatusOK, "/login")) != nil, "must fail for non-redirect response")
+	assert(t, ExpectLocationHeader("/login")(redirect(http.StatusFound, "")) != nil, "must fail for missing location")
+	assert(t, ExpectLocationPath("/login")(redirect(http.StatusFound, "http://[::1")) != nil, "must fail for invalid location")
+}
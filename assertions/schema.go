@@ -0,0 +1,152 @@
+package assertions
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"go.riyazali.net/httpx"
+	. "go.riyazali.net/httpx/helpers"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// SchemaCache is a fixed-size, least-recently-used cache of schemas fetched by ExpectSchemaRegistry(...)
+// It is safe for concurrent use by multiple goroutines.
+type SchemaCache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List               // most recently used entry is at the front
+	items map[string]*list.Element // values are of type *cacheEntry
+}
+
+type cacheEntry struct {
+	key    string
+	schema []byte
+}
+
+// NewSchemaCache returns a new SchemaCache that holds at most size schemas
+func NewSchemaCache(size int) *SchemaCache {
+	return &SchemaCache{size: size, order: list.New(), items: make(map[string]*list.Element)}
+}
+
+func (c *SchemaCache) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.order.MoveToFront(el)
+		return el.Value.(*cacheEntry).schema, true
+	}
+	return nil, false
+}
+
+func (c *SchemaCache) put(key string, schema []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		el.Value.(*cacheEntry).schema = schema
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(&cacheEntry{key: key, schema: schema})
+	for c.order.Len() > c.size {
+		var oldest = c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// default cache shared by all ExpectSchemaRegistry(...) assertions
+var defaultSchemaCache = NewSchemaCache(64)
+
+// RegistryOption customises how ExpectSchemaRegistry(...) fetches schemas from the registry
+type RegistryOption func(*registry)
+
+type registry struct {
+	client   *http.Client
+	cache    *SchemaCache
+	builders []httpx.RequestBuilder
+}
+
+// WithRegistryClient sets the http.Client used to talk to the registry (default: http.DefaultClient)
+func WithRegistryClient(client *http.Client) RegistryOption {
+	return func(r *registry) { r.client = client }
+}
+
+// WithRegistryCache sets the cache used to store fetched schemas, instead of the shared default one
+func WithRegistryCache(cache *SchemaCache) RegistryOption {
+	return func(r *registry) { r.cache = cache }
+}
+
+// WithRegistryAuth applies the builder (say, builders.WithBasicAuth(...)) to requests made to the registry
+func WithRegistryAuth(builder httpx.RequestBuilder) RegistryOption {
+	return func(r *registry) { r.builders = append(r.builders, builder) }
+}
+
+// ExpectSchemaRegistry returns an assertion that validates the json response body against a JSON Schema
+// fetched from a (Confluent compatible) schema registry, at <registryURL>/subjects/<subject>/versions/<version>
+//
+// Fetched schemas are cached to prevent repeated fetches. See helpers.ValidateJSONSchema for details on supported keywords.
+func ExpectSchemaRegistry(registryURL, subject string, version int, opts ...RegistryOption) httpx.Assertion {
+	var r = &registry{client: http.DefaultClient, cache: defaultSchemaCache}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	var endpoint = fmt.Sprintf("%s/subjects/%s/versions/%d", strings.TrimSuffix(registryURL, "/"), url.PathEscape(subject), version)
+	return BodyBytes(func(body []byte) error {
+		var schema, err = r.fetch(endpoint)
+		if err != nil {
+			return fmt.Errorf("schema registry: %v", err)
+		}
+		return ValidateJSONSchema(schema, body)
+	})
+}
+
+// fetch returns the schema at the given endpoint, either from cache or from the registry
+func (r *registry) fetch(endpoint string) (_ []byte, err error) {
+	if schema, ok := r.cache.get(endpoint); ok {
+		return schema, nil
+	}
+
+	var request *http.Request
+	if request, err = http.NewRequest(http.MethodGet, endpoint, nil); err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+	for _, fn := range r.builders {
+		if err = fn(request); err != nil {
+			return nil, err
+		}
+	}
+
+	var response *http.Response
+	if response, err = r.client.Do(request); err != nil {
+		return nil, err
+	}
+	defer checkClose(response.Body, &err)
+
+	var body []byte
+	if body, err = ioutil.ReadAll(response.Body); err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: status %d: %s", endpoint, response.StatusCode, body)
+	}
+
+	var subject struct {
+		Schema     string `json:"schema"`
+		SchemaType string `json:"schemaType"`
+	}
+	if err = json.Unmarshal(body, &subject); err != nil {
+		return nil, fmt.Errorf("failed to decode registry response: %v", err)
+	}
+	if subject.SchemaType != "" && subject.SchemaType != "JSON" {
+		return nil, fmt.Errorf("unsupported schema type '%s'", subject.SchemaType)
+	}
+
+	r.cache.put(endpoint, []byte(subject.Schema))
+	return []byte(subject.Schema), nil
+}
@@ -0,0 +1,60 @@
+package assertions_test
+
+import (
+	"encoding/json"
+	. "go.riyazali.net/httpx/assertions"
+	"go.riyazali.net/httpx/builders"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestExpectSchemaRegistry(t *testing.T) {
+	var fetches int32
+	var server = httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		if u, p, ok := request.BasicAuth(); !ok || u != "user" || p != "$ecret" {
+			writer.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch request.URL.Path {
+		case "/subjects/users-value/versions/1", "/subjects/orders-value/versions/1":
+			_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+				"subject": "users-value", "version": 1, "schemaType": "JSON",
+				"schema": `{"type": "object", "required": ["id"]}`,
+			})
+		case "/subjects/avro-value/versions/1":
+			_ = json.NewEncoder(writer).Encode(map[string]interface{}{"schema": `{"type": "record"}`, "schemaType": "AVRO"})
+		default:
+			writer.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	var cache = NewSchemaCache(1)
+	var auth = WithRegistryAuth(builders.WithBasicAuth("user", "$ecret"))
+
+	t.Run("should validate body against schema", func(t *testing.T) {
+		var expect = ExpectSchemaRegistry(server.URL, "users-value", 1, auth, WithRegistryCache(cache))
+		assert(t, expect(withBody(`{"id": 1}`)) == nil, "must pass for valid body")
+		assert(t, expect(withBody(`{"name": "alice"}`)) != nil, "must fail for invalid body")
+		assert(t, atomic.LoadInt32(&fetches) == 1, "must fetch schema only once")
+	})
+
+	t.Run("should evict least recently used schema", func(t *testing.T) {
+		_ = ExpectSchemaRegistry(server.URL, "orders-value", 1, auth, WithRegistryCache(cache))(withBody(`{"id": 1}`))
+		_ = ExpectSchemaRegistry(server.URL, "users-value", 1, auth, WithRegistryCache(cache))(withBody(`{"id": 1}`))
+		assert(t, atomic.LoadInt32(&fetches) == 3, "must re-fetch evicted schema")
+	})
+
+	t.Run("should fail if schema cannot be fetched", func(t *testing.T) {
+		assert(t, ExpectSchemaRegistry(server.URL, "users-value", 1, WithRegistryCache(NewSchemaCache(1)))(withBody(`{"id": 1}`)) != nil,
+			"must fail if registry rejects request")
+		assert(t, ExpectSchemaRegistry(server.URL, "missing", 1, auth)(withBody(`{"id": 1}`)) != nil,
+			"must fail if subject is not found")
+		assert(t, ExpectSchemaRegistry(server.URL, "avro-value", 1, auth)(withBody(`{"id": 1}`)) != nil,
+			"must fail for non-json schema")
+	})
+}
@@ -0,0 +1,107 @@
+package assertions
+
+import (
+	"encoding/json"
+	"fmt"
+	"go.riyazali.net/httpx"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ExpectOK is a shorthand for ToHaveStatus(http.StatusOK)
+func ExpectOK() httpx.Assertion { return ToHaveStatus(http.StatusOK) }
+
+// ExpectCreated is a shorthand for ToHaveStatus(http.StatusCreated)
+func ExpectCreated() httpx.Assertion { return ToHaveStatus(http.StatusCreated) }
+
+// ExpectNoContent is a shorthand for ToHaveStatus(http.StatusNoContent)
+func ExpectNoContent() httpx.Assertion { return ToHaveStatus(http.StatusNoContent) }
+
+// ExpectBadRequest is a shorthand for ToHaveStatus(http.StatusBadRequest)
+func ExpectBadRequest() httpx.Assertion { return ToHaveStatus(http.StatusBadRequest) }
+
+// ExpectUnauthorized is a shorthand for ToHaveStatus(http.StatusUnauthorized)
+func ExpectUnauthorized() httpx.Assertion { return ToHaveStatus(http.StatusUnauthorized) }
+
+// ExpectForbidden is a shorthand for ToHaveStatus(http.StatusForbidden)
+func ExpectForbidden() httpx.Assertion { return ToHaveStatus(http.StatusForbidden) }
+
+// ExpectNotFound is a shorthand for ToHaveStatus(http.StatusNotFound)
+func ExpectNotFound() httpx.Assertion { return ToHaveStatus(http.StatusNotFound) }
+
+// ExpectConflict is a shorthand for ToHaveStatus(http.StatusConflict)
+func ExpectConflict() httpx.Assertion { return ToHaveStatus(http.StatusConflict) }
+
+// ExpectUnprocessableEntity is a shorthand for ToHaveStatus(http.StatusUnprocessableEntity)
+func ExpectUnprocessableEntity() httpx.Assertion { return ToHaveStatus(http.StatusUnprocessableEntity) }
+
+// ExpectTooManyRequests is a shorthand for ToHaveStatus(http.StatusTooManyRequests)
+func ExpectTooManyRequests() httpx.Assertion { return ToHaveStatus(http.StatusTooManyRequests) }
+
+// ExpectInternalServerError is a shorthand for ToHaveStatus(http.StatusInternalServerError)
+func ExpectInternalServerError() httpx.Assertion { return ToHaveStatus(http.StatusInternalServerError) }
+
+// ExpectNoServerError returns an assertion that checks that the response doesn't indicate a server error.
+// It fails if the status is 5xx or, for json responses, if the body has a top-level "error" key
+// with a non-empty string value (bodies that are empty or not an object are not checked), as some APIs return errors with a 200 status.
+func ExpectNoServerError() httpx.Assertion {
+	return func(response *http.Response) (err error) {
+		defer checkClose(response.Body, &err)
+
+		if response.StatusCode >= 500 {
+			return fmt.Errorf("server error: returned status (%d)", response.StatusCode)
+		}
+		if !isJson(response.Header.Get("Content-Type")) {
+			return nil
+		}
+
+		var body interface{}
+		if err := json.NewDecoder(response.Body).Decode(&body); err == io.EOF {
+			return nil // empty body
+		} else if err != nil {
+			return fmt.Errorf("server error: failed to decode response body: %v", err)
+		}
+		var obj, _ = body.(map[string]interface{})
+		if msg, ok := obj["error"].(string); ok && msg != "" {
+			return fmt.Errorf("server error: status (%d) with error: %s", response.StatusCode, msg)
+		}
+		return nil
+	}
+}
+
+// ExpectReasonPhrase returns an assertion that checks that the reason phrase of the status line (like "OK" in "200 OK")
+// is equal to want. HTTP/2 (and later) responses have no reason phrase and so the assertion always passes for them.
+func ExpectReasonPhrase(want string) httpx.Assertion {
+	return func(response *http.Response) error {
+		if response.ProtoMajor >= 2 {
+			return nil
+		}
+
+		var phrase = strings.TrimPrefix(response.Status, strconv.Itoa(response.StatusCode))
+		if phrase = strings.TrimSpace(phrase); phrase != want {
+			return fmt.Errorf("status: reason phrase (%s) not equal to expected (%s)", phrase, want)
+		}
+		return nil
+	}
+}
+
+// ExpectMethodOverrideHonoured returns an assertion that checks that the response to a request made using
+// builders.WithMethodOverride(...) has the expected status, implying that the server processed the request
+// using the overridden method. If the response carries the request, it must have the X-HTTP-Method-Override header set.
+func ExpectMethodOverrideHonoured(expected int) httpx.Assertion {
+	return func(response *http.Response) error {
+		var method = "<unknown>"
+		if response.Request != nil {
+			if method = response.Request.Header.Get("X-HTTP-Method-Override"); method == "" {
+				return fmt.Errorf("method override: request doesn't have X-HTTP-Method-Override header set")
+			}
+		}
+		if response.StatusCode != expected {
+			return fmt.Errorf("method override: returned status (%d) for overridden method (%s) not equal to expected status (%d)",
+				response.StatusCode, method, expected)
+		}
+		return nil
+	}
+}
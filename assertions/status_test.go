@@ -0,0 +1,89 @@
+package assertions_test
+
+import (
+	"go.riyazali.net/httpx"
+	. "go.riyazali.net/httpx/assertions"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSemanticStatus(t *testing.T) {
+	var cases = map[int]httpx.Assertion{
+		http.StatusOK:                  ExpectOK(),
+		http.StatusCreated:             ExpectCreated(),
+		http.StatusNoContent:           ExpectNoContent(),
+		http.StatusBadRequest:          ExpectBadRequest(),
+		http.StatusUnauthorized:        ExpectUnauthorized(),
+		http.StatusForbidden:           ExpectForbidden(),
+		http.StatusNotFound:            ExpectNotFound(),
+		http.StatusConflict:            ExpectConflict(),
+		http.StatusUnprocessableEntity: ExpectUnprocessableEntity(),
+		http.StatusTooManyRequests:     ExpectTooManyRequests(),
+		http.StatusInternalServerError: ExpectInternalServerError(),
+	}
+
+	for status, assertion := range cases {
+		var writer = httptest.NewRecorder()
+		writer.WriteHeader(status)
+		assert(t, assertion(writer.Result()) == nil, "must pass for status %d", status)
+
+		var other = httptest.NewRecorder()
+		other.WriteHeader(http.StatusTeapot)
+		assert(t, assertion(other.Result()) != nil, "must fail for status other than %d", status)
+	}
+}
+
+func TestExpectNoServerError(t *testing.T) {
+	var response = func(status int, contentType, body string) *http.Response {
+		var writer = httptest.NewRecorder()
+		writer.Header().Set("Content-Type", contentType)
+		writer.WriteHeader(status)
+		_, _ = io.WriteString(writer, body)
+		return writer.Result()
+	}
+
+	assert(t, ExpectNoServerError()(response(http.StatusOK, "application/json", `{"data": 1}`)) == nil, "must pass for success")
+	assert(t, ExpectNoServerError()(response(http.StatusNotFound, "text/plain", "not found")) == nil, "must pass for client error")
+	assert(t, ExpectNoServerError()(response(http.StatusOK, "text/plain", `{"error": "x"}`)) == nil, "must skip json check for non-json")
+	assert(t, ExpectNoServerError()(response(http.StatusOK, "application/json", `{"error": ""}`)) == nil, "must pass for empty error")
+	assert(t, ExpectNoServerError()(response(http.StatusOK, "application/json", `{"error": {"code": 1}}`)) == nil, "must pass for non-string error")
+	assert(t, ExpectNoServerError()(response(http.StatusOK, "application/json", `[1, 2]`)) == nil, "must pass for array body")
+	assert(t, ExpectNoServerError()(response(http.StatusOK, "application/json", `"ok"`)) == nil, "must pass for scalar body")
+	assert(t, ExpectNoServerError()(response(http.StatusOK, "application/json", "")) == nil, "must pass for empty body")
+	assert(t, ExpectNoServerError()(response(http.StatusOK, "application/json", `{"error"`)) != nil, "must fail for invalid json")
+	assert(t, ExpectNoServerError()(response(http.StatusBadGateway, "text/plain", "")) != nil, "must fail for 5xx status")
+
+	var err = ExpectNoServerError()(response(http.StatusOK, "application/json; charset=utf-8", `{"error": "internal error"}`))
+	assert(t, err != nil && strings.Contains(err.Error(), "internal error") && strings.Contains(err.Error(), "200"),
+		"must fail with status and error for json error")
+}
+
+func TestExpectReasonPhrase(t *testing.T) {
+	var response = func(proto int, status string) *http.Response {
+		return &http.Response{Status: status, StatusCode: http.StatusNotFound, ProtoMajor: proto}
+	}
+
+	assert(t, ExpectReasonPhrase("Not Found")(response(1, "404 Not Found")) == nil, "must pass for same phrase")
+	assert(t, ExpectReasonPhrase("Gone Fishing")(response(1, "404 Gone Fishing")) == nil, "must pass for custom phrase")
+	assert(t, ExpectReasonPhrase("Not Found")(response(1, "404 Nope")) != nil, "must fail for different phrase")
+	assert(t, ExpectReasonPhrase("Not Found")(response(1, "404")) != nil, "must fail for missing phrase")
+	assert(t, ExpectReasonPhrase("Not Found")(response(2, "404")) == nil, "must skip HTTP/2 responses")
+}
+
+func TestExpectMethodOverrideHonoured(t *testing.T) {
+	var response = func(status int, override string) *http.Response {
+		var request, _ = http.NewRequest(http.MethodPost, "/users/1", nil)
+		if override != "" {
+			request.Header.Set("X-HTTP-Method-Override", override)
+		}
+		return &http.Response{StatusCode: status, Request: request}
+	}
+
+	assert(t, ExpectMethodOverrideHonoured(http.StatusNoContent)(response(http.StatusNoContent, "DELETE")) == nil, "must pass for expected status")
+	assert(t, ExpectMethodOverrideHonoured(http.StatusNoContent)(response(http.StatusMethodNotAllowed, "DELETE")) != nil, "must fail for other status")
+	assert(t, ExpectMethodOverrideHonoured(http.StatusNoContent)(response(http.StatusNoContent, "")) != nil, "must fail if request wasn't overridden")
+	assert(t, ExpectMethodOverrideHonoured(http.StatusOK)(&http.Response{StatusCode: http.StatusOK}) == nil, "must pass without request")
+}
@@ -3,7 +3,9 @@ package assertions
 import (
 	"go.riyazali.net/httpx"
 	"io"
+	"mime"
 	"net/http"
+	"strings"
 )
 
 // CheckClose calls Close on the given io.Closer. If the given *error points to
@@ -23,3 +25,9 @@ func failed(err error) httpx.Assertion {
 		return err
 	}
 }
+
+// isJson reports whether the given content type denotes a json document (like application/json or application/problem+json)
+func isJson(contentType string) bool {
+	var mt, _, err = mime.ParseMediaType(contentType)
+	return err == nil && (mt == "application/json" || (strings.HasPrefix(mt, "application/") && strings.HasSuffix(mt, "+json")))
+}
@@ -0,0 +1,47 @@
+package assertions
+
+import (
+	"encoding/xml"
+	"fmt"
+	"go.riyazali.net/httpx"
+	"io"
+	"net/http"
+)
+
+// ExpectXMLCount returns an assertion that counts the elements with the given (local) name,
+// in any namespace, in the xml response body and compares it to want.
+func ExpectXMLCount(elementName string, want int) httpx.Assertion {
+	return xmlCount(func(name xml.Name) bool { return name.Local == elementName }, elementName, want)
+}
+
+// ExpectXMLCountNS is like ExpectXMLCount but only counts elements in the given namespace.
+func ExpectXMLCountNS(namespace, localName string, want int) httpx.Assertion {
+	return xmlCount(func(name xml.Name) bool { return name.Space == namespace && name.Local == localName },
+		fmt.Sprintf("{%s}%s", namespace, localName), want)
+}
+
+// xmlCount returns an assertion that counts elements matched by the given predicate
+func xmlCount(match func(xml.Name) bool, label string, want int) httpx.Assertion {
+	return func(response *http.Response) (err error) {
+		defer checkClose(response.Body, &err)
+
+		var n = 0
+		var decoder = xml.NewDecoder(response.Body)
+		for {
+			var token, err = decoder.Token()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return fmt.Errorf("xml: failed to decode response body: %v", err)
+			}
+			if start, ok := token.(xml.StartElement); ok && match(start.Name) {
+				n++
+			}
+		}
+
+		if n != want {
+			return fmt.Errorf("xml: found %d element(s) matching '%s', expected %d", n, label, want)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,32 @@
+package assertions_test
+
+import (
+	. "go.riyazali.net/httpx/assertions"
+	"testing"
+)
+
+func TestExpectXMLCount(t *testing.T) {
+	var body = `<?xml version="1.0"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xmlns:x="urn:x">
+  <entry><title>a</title></entry>
+  <entry><title>b</title></entry>
+  <x:entry><title>c</title></x:entry>
+</feed>`
+
+	t.Run("should count elements by name", func(t *testing.T) {
+		assert(t, ExpectXMLCount("entry", 3)(withBody(body)) == nil, "must count elements in all namespaces")
+		assert(t, ExpectXMLCount("title", 3)(withBody(body)) == nil, "must count nested elements")
+		assert(t, ExpectXMLCount("entry", 2)(withBody(body)) != nil, "must fail if count doesn't match")
+		assert(t, ExpectXMLCount("missing", 0)(withBody(body)) == nil, "must pass for zero count")
+	})
+
+	t.Run("should count elements by namespace", func(t *testing.T) {
+		assert(t, ExpectXMLCountNS("http://www.w3.org/2005/Atom", "entry", 2)(withBody(body)) == nil, "must count elements in namespace")
+		assert(t, ExpectXMLCountNS("urn:x", "entry", 1)(withBody(body)) == nil, "must count elements in prefixed namespace")
+		assert(t, ExpectXMLCountNS("urn:y", "entry", 1)(withBody(body)) != nil, "must fail if count doesn't match")
+	})
+
+	t.Run("should fail for malformed xml", func(t *testing.T) {
+		assert(t, ExpectXMLCount("entry", 1)(withBody("<feed><entry></feed>")) != nil, "must fail for malformed xml")
+	})
+}
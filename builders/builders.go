@@ -2,11 +2,49 @@
 package builders // import "go.riyazali.net/httpx/builders"
 
 import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"go.riyazali.net/httpx"
+	"go.riyazali.net/httpx/helpers"
+	"io"
+	"io/ioutil"
+	"mime"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
+// clock used by builders that depend on current time; see SetClock(...)
+var clock = struct {
+	sync.RWMutex
+	now func() time.Time
+}{now: time.Now}
+
+// SetClock sets the clock used by builders (like WithDate) that depend on the current time.
+// The default is time.Now. Tests can use it to make date sensitive headers deterministic, such as,
+//    SetClock(func() time.Time { return fixedTime })
+//    defer SetClock(time.Now)
+func SetClock(fn func() time.Time) {
+	clock.Lock()
+	defer clock.Unlock()
+	clock.now = fn
+}
+
+// now returns the current time as reported by the clock
+func now() time.Time {
+	clock.RLock()
+	defer clock.RUnlock()
+	return clock.now()
+}
+
 // WithHeader takes in a header name and one or more values and returns a RequestBuilder.
 // The first value is added using header.Add(...) method whereas remaining values are
 // added using header.Set(...). See net/http.Header more info.
@@ -38,6 +76,56 @@ func WithAuthorization(scheme, credentials string) httpx.RequestBuilder {
 	return WithHeader("Authorization", fmt.Sprintf("%s %s", scheme, credentials))
 }
 
+// WithDate sets the Date header on the request to the current time, as reported by the clock. See SetClock(...)
+func WithDate() httpx.RequestBuilder {
+	return func(request *http.Request) error {
+		request.Header.Set("Date", now().UTC().Format(http.TimeFormat))
+		return nil
+	}
+}
+
+// WithTraceID returns a RequestBuilder that sets a W3C traceparent header (see https://www.w3.org/TR/trace-context/)
+// with the given trace id and a random parent id, marking the trace as sampled. Use assertions.ExpectSameTraceID(...)
+// to check that the server propagated it.
+func WithTraceID(id [16]byte) httpx.RequestBuilder {
+	return func(request *http.Request) error {
+		var parent [8]byte
+		if _, err := rand.Read(parent[:]); err != nil {
+			return fmt.Errorf("trace id: failed to generate parent id: %v", err)
+		}
+		request.Header.Set("traceparent", fmt.Sprintf("00-%s-%s-01", hex.EncodeToString(id[:]), hex.EncodeToString(parent[:])))
+		return nil
+	}
+}
+
+// WithMethodOverride returns a RequestBuilder that sets the X-HTTP-Method-Override header to the given method,
+// for APIs (usually behind firewalls that block methods like PUT or DELETE) that tunnel the real method over POST.
+// It must be used with a POST request, such as,
+//    MakeRequest(Post("/users/1", nil), WithMethodOverride(http.MethodDelete)).ExpectIt(t, ExpectMethodOverrideHonoured(http.StatusNoContent))
+func WithMethodOverride(realMethod string) httpx.RequestBuilder {
+	return func(request *http.Request) error {
+		if request.Method != http.MethodPost {
+			return fmt.Errorf("method override: request method must be POST, got %s", request.Method)
+		}
+		request.Header.Set("X-HTTP-Method-Override", strings.ToUpper(realMethod))
+		return nil
+	}
+}
+
+// WithSignedCookie returns a RequestBuilder that adds a cookie with the given name and a value signed using
+// helpers.SignCookie(...), for testing middleware that validates signed cookies. The cookie's value is
+//    value + "|" + base64url(HMAC(secret, name + "=" + value))
+func WithSignedCookie(name, value, secret string, hashFunc crypto.Hash) httpx.RequestBuilder {
+	return func(request *http.Request) error {
+		var signed, err = helpers.SignCookie(name, value, secret, hashFunc)
+		if err != nil {
+			return fmt.Errorf("signed cookie: %v", err)
+		}
+		request.AddCookie(&http.Cookie{Name: name, Value: signed})
+		return nil
+	}
+}
+
 // WithHost changes the host value used by the request.
 // By default outgoing requests use the value from url.Host for Host header. Setting this overrides
 // the default behaviour and changes the Host header sent in the request.
@@ -47,3 +135,255 @@ func WithHost(host string) httpx.RequestBuilder {
 		return nil
 	}
 }
+
+// WithHostOverride is like WithHost but also sets the Host header on the request.
+// While outgoing requests only use request.Host, handlers invoked directly (using executors.WithHandler)
+// might also look at request.Header, and so this keeps both in sync to make virtual-host routing testable.
+func WithHostOverride(host string) httpx.RequestBuilder {
+	return func(request *http.Request) error {
+		request.Host = host
+		request.Header.Set("Host", host)
+		return nil
+	}
+}
+
+// WithPathParam replaces the {name} placeholder in request's url path with the (path escaped) value.
+// It returns an error if the placeholder is not found in the path, to help catch typos early.
+// Multiple WithPathParam(...) builders can be combined, each replacing a single placeholder.
+//
+//    MakeRequest(Get("/users/{id}/posts/{post}"), WithPathParam("id", "1"), WithPathParam("post", "2"))
+func WithPathParam(name, value string) httpx.RequestBuilder {
+	return func(request *http.Request) error {
+		var placeholder = url.PathEscape("{" + name + "}")
+		var escaped = request.URL.EscapedPath()
+		if !strings.Contains(escaped, placeholder) {
+			return fmt.Errorf("path param: placeholder {%s} not found in path '%s'", name, request.URL.Path)
+		}
+
+		escaped = strings.Replace(escaped, placeholder, url.PathEscape(value), 1)
+		var path, err = url.PathUnescape(escaped)
+		if err != nil {
+			return fmt.Errorf("path param: %v", err)
+		}
+		request.URL.Path, request.URL.RawPath = path, escaped
+		return nil
+	}
+}
+
+// WithTrailingSlash returns a RequestBuilder that makes sure the request path ends with a '/', for routers
+// that treat "/users" and "/users/" differently. See WithoutTrailingSlash(...)
+func WithTrailingSlash() httpx.RequestBuilder {
+	return func(request *http.Request) error {
+		if !strings.HasSuffix(request.URL.Path, "/") {
+			request.URL.Path += "/"
+			if request.URL.RawPath != "" {
+				request.URL.RawPath += "/"
+			}
+		}
+		return nil
+	}
+}
+
+// WithoutTrailingSlash returns a RequestBuilder that strips any trailing '/' from the request path.
+// The root path ("/") is left as-is. See WithTrailingSlash(...)
+func WithoutTrailingSlash() httpx.RequestBuilder {
+	return func(request *http.Request) error {
+		var trim = func(p string) string {
+			if t := strings.TrimRight(p, "/"); t != "" {
+				return t
+			}
+			return "/"
+		}
+		if request.URL.Path != "" {
+			request.URL.Path = trim(request.URL.Path)
+		}
+		if request.URL.RawPath != "" {
+			request.URL.RawPath = trim(request.URL.RawPath)
+		}
+		return nil
+	}
+}
+
+// WithQueryParamFromResponse returns a RequestBuilder that adds a query parameter whose value
+// is read from source at build time. It is meant to chain values across requests in workflow tests,
+// where source is populated by an assertion on a previous response. It returns an error if *source is empty.
+//
+//    var cursor string
+//    exec.MakeRequest(Get("/items")).ExpectIt(t,
+//        assertions.WithHeader("X-Next-Cursor", func(v string) error { cursor = v; return nil }))
+//    exec.MakeRequest(Get("/items"), WithQueryParamFromResponse("cursor", &cursor)).ExpectIt(t, ...)
+func WithQueryParamFromResponse(param string, source *string) httpx.RequestBuilder {
+	return func(request *http.Request) error {
+		if source == nil || *source == "" {
+			return fmt.Errorf("query param: value for '%s' is empty", param)
+		}
+		var q = request.URL.Query()
+		q.Add(param, *source)
+		request.URL.RawQuery = q.Encode()
+		return nil
+	}
+}
+
+// WithRequestBodyLogger returns a RequestBuilder that writes the request body, preceded by a header line, to w.
+// The body is restored on the request after reading so that it can still be sent out. Add it after all builders
+// that modify the body (signing, compression etc.) to see the exact bytes that'd be sent.
+func WithRequestBodyLogger(w io.Writer) httpx.RequestBuilder {
+	return func(request *http.Request) error {
+		var body, err = readBody(request)
+		if err != nil {
+			return fmt.Errorf("body logger: failed to read request body: %v", err)
+		}
+
+		if _, err := fmt.Fprintf(w, "--- request body: %s %s (%d bytes)\n%s\n", request.Method, request.URL, len(body), body); err != nil {
+			return fmt.Errorf("body logger: %v", err)
+		}
+		return nil
+	}
+}
+
+// WithRequestBodyValidator returns a RequestBuilder that validates the request body against the given JSON Schema
+// (see helpers.ValidateJSONSchema(...) for the supported keywords) and fails with the violations found, if any.
+// This catches malformed test fixtures before they are sent out, instead of debugging a cryptic 400 response.
+// Add it after all builders that set or modify the body.
+func WithRequestBodyValidator(schema []byte) httpx.RequestBuilder {
+	return func(request *http.Request) error {
+		var body, err = readBody(request)
+		if err != nil {
+			return fmt.Errorf("body validator: failed to read request body: %v", err)
+		}
+		if err = helpers.ValidateJSONSchema(schema, body); err != nil {
+			return fmt.Errorf("body validator: request body doesn't match schema: %v", err)
+		}
+		return nil
+	}
+}
+
+// WithFlattenedJSONBody returns a RequestBuilder that marshals each of the parts (structs, maps etc.) into a json object,
+// merges their top-level keys into a single object (with keys in later parts overriding the ones in earlier parts)
+// and sets that as the request body. The Content-Type is set to application/json, unless already set.
+// This allows composing bodies out of shared and test-specific fields in table-driven tests, such as,
+//    WithFlattenedJSONBody(baseUser, map[string]interface{}{"email": "invalid"})
+func WithFlattenedJSONBody(parts ...interface{}) httpx.RequestBuilder {
+	return func(request *http.Request) error {
+		var merged = make(map[string]json.RawMessage)
+		for i, part := range parts {
+			var b, err = json.Marshal(part)
+			if err != nil {
+				return fmt.Errorf("flattened json: failed to marshal part #%d: %v", i, err)
+			}
+
+			var fields map[string]json.RawMessage
+			if err = json.Unmarshal(b, &fields); err != nil || fields == nil {
+				return fmt.Errorf("flattened json: part #%d is not a json object", i)
+			}
+			for key, value := range fields {
+				merged[key] = value
+			}
+		}
+
+		var body, err = json.Marshal(merged)
+		if err != nil {
+			return fmt.Errorf("flattened json: failed to marshal body: %v", err)
+		}
+		setBody(request, body)
+		if request.Header.Get("Content-Type") == "" {
+			request.Header.Set("Content-Type", "application/json")
+		}
+		return nil
+	}
+}
+
+// SOAP envelope namespaces, keyed by SOAP version
+var soapNamespaces = map[string]string{
+	"1.1": "http://schemas.xmlsoap.org/soap/envelope/",
+	"1.2": "http://www.w3.org/2003/05/soap-envelope",
+}
+
+// soapEnvelope is used to marshal the envelope for WithSOAPBody(...)
+type soapEnvelope struct {
+	XMLName   xml.Name `xml:"soap:Envelope"`
+	Namespace string   `xml:"xmlns:soap,attr"`
+	Body      struct {
+		Raw string `xml:",innerxml"`
+	} `xml:"soap:Body"`
+}
+
+// WithSOAPBody returns a RequestBuilder that sets the request body to a SOAP envelope of the given version (either "1.1"
+// or "1.2") wrapping body, which is either marshalled using encoding/xml or, if it's a string or []byte, used as-is.
+// A struct without an XMLName field is marshalled into an element named after its type.
+// It sets the Content-Type to text/xml for SOAP 1.1 and to application/soap+xml for SOAP 1.2 (see WithSOAPAction(...)).
+//
+//    MakeRequest(Post("/ws/users", nil), WithSOAPBody(GetUser{ID: 1}, "1.1"), WithSOAPAction("urn:GetUser"))
+func WithSOAPBody(body interface{}, soapVersion string) httpx.RequestBuilder {
+	return func(request *http.Request) error {
+		var ns, ok = soapNamespaces[soapVersion]
+		if !ok {
+			return fmt.Errorf("soap: unsupported version '%s'", soapVersion)
+		}
+
+		var envelope = soapEnvelope{Namespace: ns}
+		switch b := body.(type) {
+		case string:
+			envelope.Body.Raw = b
+		case []byte:
+			envelope.Body.Raw = string(b)
+		default:
+			var payload, err = xml.Marshal(body)
+			if err != nil {
+				return fmt.Errorf("soap: failed to marshal body: %v", err)
+			}
+			envelope.Body.Raw = string(payload)
+		}
+
+		var buf bytes.Buffer
+		buf.WriteString(xml.Header)
+		if err := xml.NewEncoder(&buf).Encode(envelope); err != nil {
+			return fmt.Errorf("soap: failed to marshal envelope: %v", err)
+		}
+
+		setBody(request, buf.Bytes())
+		if soapVersion == "1.1" {
+			request.Header.Set("Content-Type", "text/xml; charset=utf-8")
+		} else {
+			request.Header.Set("Content-Type", "application/soap+xml; charset=utf-8")
+		}
+		return nil
+	}
+}
+
+// WithSOAPAction returns a RequestBuilder that sets the SOAPAction header used by SOAP 1.1 services. For SOAP 1.2
+// requests (with an application/soap+xml Content-Type), the action is also added as a parameter to the Content-Type.
+// Add it after WithSOAPBody(...)
+func WithSOAPAction(action string) httpx.RequestBuilder {
+	return func(request *http.Request) error {
+		request.Header.Set("SOAPAction", strconv.Quote(action))
+		if mt, params, err := mime.ParseMediaType(request.Header.Get("Content-Type")); err == nil && mt == "application/soap+xml" {
+			params["action"] = action
+			request.Header.Set("Content-Type", mime.FormatMediaType(mt, params))
+		}
+		return nil
+	}
+}
+
+// readBody reads and returns the request body, restoring it on the request (along with GetBody)
+// so that it can be read again when the request is sent out.
+func readBody(request *http.Request) ([]byte, error) {
+	if request.Body == nil || request.Body == http.NoBody {
+		return nil, nil
+	}
+
+	var body, err = ioutil.ReadAll(request.Body)
+	if err != nil {
+		return nil, err
+	}
+	_ = request.Body.Close()
+	setBody(request, body)
+	return body, nil
+}
+
+// setBody sets body as the request body, along with GetBody and ContentLength
+func setBody(request *http.Request, body []byte) {
+	request.Body = ioutil.NopCloser(bytes.NewReader(body))
+	request.GetBody = func() (io.ReadCloser, error) { return ioutil.NopCloser(bytes.NewReader(body)), nil }
+	request.ContentLength = int64(len(body))
+}
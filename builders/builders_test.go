@@ -2,8 +2,14 @@ package builders
 
 import (
 	"bytes"
+	"crypto"
+	_ "crypto/sha256"
+	"encoding/xml"
+	"io/ioutil"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 )
 
 func assert(t *testing.T, cond bool, msg string, args ...interface{}) {
@@ -69,9 +75,237 @@ func TestWithAuthorization(t *testing.T) {
 	assert(t, r.Header.Get("Authorization") == "Bearer token", "Authorization header must be set")
 }
 
+func TestWithDate(t *testing.T) {
+	var fixed = time.Date(2020, time.June, 24, 10, 30, 0, 0, time.UTC)
+	SetClock(func() time.Time { return fixed })
+	defer SetClock(time.Now)
+
+	var r = newRequest()
+	var err = WithDate()(r)
+	require(t, err == nil, "builder must not return error")
+	assert(t, r.Header.Get("Date") == "Wed, 24 Jun 2020 10:30:00 GMT", "must set date from clock")
+}
+
 func TestWithHost(t *testing.T) {
 	var r = newRequest()
 	var err = WithHost("httpbin.org")(r)
 	require(t, err == nil, "builder must not return error")
 	assert(t, r.Host == "httpbin.org", "host must be overridden")
 }
+
+func TestWithHostOverride(t *testing.T) {
+	var r = newRequest()
+	var err = WithHostOverride("httpbin.org")(r)
+	require(t, err == nil, "builder must not return error")
+	assert(t, r.Host == "httpbin.org", "host must be overridden")
+	assert(t, r.Header.Get("Host") == "httpbin.org", "host header must be set")
+}
+
+func TestWithPathParam(t *testing.T) {
+	var newRequest = func(path string) *http.Request {
+		request, _ := http.NewRequest(http.MethodGet, "https://example.com"+path, nil)
+		return request
+	}
+
+	t.Run("should replace placeholders", func(t *testing.T) {
+		var r = newRequest("/users/{id}/posts/{post}")
+		require(t, WithPathParam("id", "1")(r) == nil, "builder must not return error")
+		require(t, WithPathParam("post", "a b/c")(r) == nil, "builder must not return error")
+		assert(t, r.URL.Path == "/users/1/posts/a b/c", "must replace placeholders in path")
+		assert(t, r.URL.String() == "https://example.com/users/1/posts/a%20b%2Fc", "must escape value in url")
+	})
+
+	t.Run("should return error if placeholder is not found", func(t *testing.T) {
+		var r = newRequest("/users/{id}")
+		assert(t, WithPathParam("user", "1")(r) != nil, "builder must return error for missing placeholder")
+	})
+}
+
+func TestWithQueryParamFromResponse(t *testing.T) {
+	t.Run("should add query param from source", func(t *testing.T) {
+		var r = newRequest()
+		var source string
+		var builder = WithQueryParamFromResponse("cursor", &source)
+
+		source = "abc 123" // populated after builder is created
+		require(t, builder(r) == nil, "builder must not return error")
+		assert(t, r.URL.Query().Get("cursor") == "abc 123", "must add query param with value from source")
+	})
+
+	t.Run("should return error if source is empty", func(t *testing.T) {
+		var source string
+		assert(t, WithQueryParamFromResponse("cursor", &source)(newRequest()) != nil, "builder must return error for empty source")
+		assert(t, WithQueryParamFromResponse("cursor", nil)(newRequest()) != nil, "builder must return error for nil source")
+	})
+}
+
+func TestWithRequestBodyLogger(t *testing.T) {
+	t.Run("should log and restore body", func(t *testing.T) {
+		var r, _ = http.NewRequest(http.MethodPost, "https://example.com/users", strings.NewReader("{\"a\":1}"))
+		var buf bytes.Buffer
+		require(t, WithRequestBodyLogger(&buf)(r) == nil, "builder must not return error")
+
+		assert(t, buf.String() == "--- request body: POST https://example.com/users (7 bytes)\n{\"a\":1}\n", "must log body with header line")
+		var body, _ = ioutil.ReadAll(r.Body)
+		assert(t, string(body) == "{\"a\":1}", "must restore request body")
+	})
+
+	t.Run("should log empty body", func(t *testing.T) {
+		var buf bytes.Buffer
+		require(t, WithRequestBodyLogger(&buf)(newRequest()) == nil, "builder must not return error")
+		assert(t, strings.Contains(buf.String(), "(0 bytes)"), "must log empty body")
+	})
+}
+
+func TestWithRequestBodyValidator(t *testing.T) {
+	var schema = []byte(`{"type": "object", "required": ["name"], "properties": {"name": {"type": "string"}}}`)
+	var request = func(body string) *http.Request {
+		var r, _ = http.NewRequest(http.MethodPost, "https://example.com/users", strings.NewReader(body))
+		return r
+	}
+
+	t.Run("should pass and restore valid body", func(t *testing.T) {
+		var r = request(`{"name": "john"}`)
+		require(t, WithRequestBodyValidator(schema)(r) == nil, "builder must not return error")
+		var body, _ = ioutil.ReadAll(r.Body)
+		assert(t, string(body) == `{"name": "john"}`, "must restore request body")
+	})
+
+	t.Run("should fail for invalid body", func(t *testing.T) {
+		var err = WithRequestBodyValidator(schema)(request(`{"name": 1}`))
+		assert(t, err != nil && strings.Contains(err.Error(), "$.name"), "must fail with violations, got: %v", err)
+		assert(t, WithRequestBodyValidator(schema)(request(`{}`)) != nil, "must fail for missing property")
+		assert(t, WithRequestBodyValidator(schema)(request(`not json`)) != nil, "must fail for malformed body")
+		assert(t, WithRequestBodyValidator(schema)(newRequest()) != nil, "must fail for empty body")
+	})
+}
+
+func TestWithTraceID(t *testing.T) {
+	var id = [16]byte{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+	var r = newRequest()
+	require(t, WithTraceID(id)(r) == nil, "builder must not return error")
+
+	var header = r.Header.Get("traceparent")
+	assert(t, strings.HasPrefix(header, "00-4bf92f3577b34da6a3ce929d0e0e4736-") && strings.HasSuffix(header, "-01"),
+		"must set traceparent with trace id, got: %s", header)
+	assert(t, len(header) == 55, "must set a 16 hex digit parent id, got: %s", header)
+}
+
+func TestWithMethodOverride(t *testing.T) {
+	var r, _ = http.NewRequest(http.MethodPost, "/users/1", nil)
+	require(t, WithMethodOverride("delete")(r) == nil, "builder must not return error")
+	assert(t, r.Header.Get("X-HTTP-Method-Override") == "DELETE", "must set override header")
+	assert(t, r.Method == http.MethodPost, "must not change request method")
+
+	assert(t, WithMethodOverride(http.MethodDelete)(newRequest()) != nil, "must fail for non-POST request")
+}
+
+func TestWithSignedCookie(t *testing.T) {
+	var r = newRequest()
+	require(t, WithSignedCookie("session", "user-1", "s3cr3t", crypto.SHA256)(r) == nil, "builder must not return error")
+
+	var c, err = r.Cookie("session")
+	require(t, err == nil, "must set cookie")
+	assert(t, c.Value == "user-1|2EjcDUlwcDLLRLLqz9s7hjOfxJWQcz9vyb9QwJpEInM=", "must set signed value, got: %s", c.Value)
+
+	assert(t, WithSignedCookie("session", "user-1", "s3cr3t", crypto.Hash(0))(newRequest()) != nil, "must fail for unavailable hash")
+}
+
+func TestWithSOAPBody(t *testing.T) {
+	type getUser struct {
+		XMLName xml.Name `xml:"urn:users GetUser"`
+		ID      int      `xml:"id"`
+	}
+
+	t.Run("should wrap body in SOAP 1.1 envelope", func(t *testing.T) {
+		var r = newRequest()
+		require(t, WithSOAPBody(getUser{ID: 1}, "1.1")(r) == nil, "builder must not return error")
+		require(t, WithSOAPAction("urn:users/GetUser")(r) == nil, "builder must not return error")
+
+		var body, _ = ioutil.ReadAll(r.Body)
+		assert(t, string(body) == xml.Header+`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">`+
+			`<soap:Body><GetUser xmlns="urn:users"><id>1</id></GetUser></soap:Body></soap:Envelope>`, "must set envelope, got: %s", body)
+		assert(t, r.ContentLength == int64(len(body)), "must set content length")
+		assert(t, r.Header.Get("Content-Type") == "text/xml; charset=utf-8", "must set content type")
+		assert(t, r.Header.Get("SOAPAction") == `"urn:users/GetUser"`, "must set quoted action")
+	})
+
+	t.Run("should wrap raw body in SOAP 1.2 envelope", func(t *testing.T) {
+		var r = newRequest()
+		require(t, WithSOAPBody(`<GetUser><id>2</id></GetUser>`, "1.2")(r) == nil, "builder must not return error")
+		require(t, WithSOAPAction("urn:users/GetUser")(r) == nil, "builder must not return error")
+
+		var body, _ = ioutil.ReadAll(r.Body)
+		assert(t, strings.Contains(string(body), `<soap:Envelope xmlns:soap="http://www.w3.org/2003/05/soap-envelope">`+
+			`<soap:Body><GetUser><id>2</id></GetUser></soap:Body>`), "must embed raw body, got: %s", body)
+		assert(t, r.Header.Get("Content-Type") == `application/soap+xml; action="urn:users/GetUser"; charset=utf-8`,
+			"must add action to content type, got: %s", r.Header.Get("Content-Type"))
+	})
+
+	t.Run("should name element after type without XMLName", func(t *testing.T) {
+		type GetUser struct {
+			ID int `xml:"id"`
+		}
+
+		var r = newRequest()
+		require(t, WithSOAPBody(GetUser{ID: 1}, "1.1")(r) == nil, "builder must not return error")
+
+		var body, _ = ioutil.ReadAll(r.Body)
+		assert(t, strings.Contains(string(body), `<soap:Body><GetUser><id>1</id></GetUser></soap:Body>`),
+			"must not wrap body in another element, got: %s", body)
+	})
+
+	t.Run("should fail for unsupported version", func(t *testing.T) {
+		assert(t, WithSOAPBody(getUser{}, "2.0")(newRequest()) != nil, "must fail for unsupported version")
+	})
+}
+
+func TestWithFlattenedJSONBody(t *testing.T) {
+	type base struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}
+
+	t.Run("should merge parts", func(t *testing.T) {
+		var r = newRequest()
+		var err = WithFlattenedJSONBody(base{Name: "john", Email: "john@example.com"}, map[string]interface{}{"email": "invalid", "age": 42})(r)
+		require(t, err == nil, "builder must not return error: %v", err)
+
+		var body, _ = ioutil.ReadAll(r.Body)
+		assert(t, string(body) == `{"age":42,"email":"invalid","name":"john"}`, "must merge parts with later keys winning, got: %s", body)
+		assert(t, r.ContentLength == int64(len(body)), "must set content length")
+		assert(t, r.Header.Get("Content-Type") == "application/json", "must set content type")
+	})
+
+	t.Run("should retain content type", func(t *testing.T) {
+		var r = newRequest()
+		r.Header.Set("Content-Type", "application/merge-patch+json")
+		require(t, WithFlattenedJSONBody(base{})(r) == nil, "builder must not return error")
+		assert(t, r.Header.Get("Content-Type") == "application/merge-patch+json", "must not override content type")
+	})
+
+	t.Run("should fail for non-object parts", func(t *testing.T) {
+		assert(t, WithFlattenedJSONBody(base{}, []int{1})(newRequest()) != nil, "must fail for array")
+		assert(t, WithFlattenedJSONBody(nil)(newRequest()) != nil, "must fail for null")
+		assert(t, WithFlattenedJSONBody(make(chan int))(newRequest()) != nil, "must fail for unmarshallable value")
+	})
+}
+
+func TestWithTrailingSlash(t *testing.T) {
+	var path = func(u string, builder func(*http.Request) error) string {
+		var r, _ = http.NewRequest(http.MethodGet, u, nil)
+		require(t, builder(r) == nil, "builder must not return error")
+		return r.URL.String()
+	}
+
+	assert(t, path("https://example.com/users", WithTrailingSlash()) == "https://example.com/users/", "must add trailing slash")
+	assert(t, path("https://example.com/users/", WithTrailingSlash()) == "https://example.com/users/", "must not add another slash")
+	assert(t, path("https://example.com", WithTrailingSlash()) == "https://example.com/", "must add slash to empty path")
+	assert(t, path("https://example.com/a%2Fb?q=1", WithTrailingSlash()) == "https://example.com/a%2Fb/?q=1", "must retain escaping and query")
+
+	assert(t, path("https://example.com/users/", WithoutTrailingSlash()) == "https://example.com/users", "must strip trailing slash")
+	assert(t, path("https://example.com/users//", WithoutTrailingSlash()) == "https://example.com/users", "must strip all trailing slashes")
+	assert(t, path("https://example.com/users", WithoutTrailingSlash()) == "https://example.com/users", "must not change path without slash")
+	assert(t, path("https://example.com/", WithoutTrailingSlash()) == "https://example.com/", "must retain root path")
+	assert(t, path("https://example.com/a%2Fb/", WithoutTrailingSlash()) == "https://example.com/a%2Fb", "must retain escaping")
+}
@@ -0,0 +1,88 @@
+package httpx
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+)
+
+// RequestChain defines a multi-step workflow (say, create, fetch and then delete a resource) where each step
+// is a request that could depend on the response of the previous one. The zero value is an empty chain ready to use.
+//
+//  new(RequestChain).
+//    Step("create", http.MethodPost, "/users", WithHeader("Content-Type", "application/json")).
+//    Step("fetch", http.MethodGet, "/users").
+//    WithResponseHandler(func(prev *http.Response, next *http.Request) error {
+//      next.URL.Path = prev.Header.Get("Location")
+//      return nil
+//    }).
+//    Run(t, WithDefaultClient())
+type RequestChain struct {
+	steps    []chainStep
+	handlers []func(prev *http.Response, next *http.Request) error
+}
+
+// chainStep is a single step in a RequestChain
+type chainStep struct {
+	name        string
+	method, url string
+	builders    []RequestBuilder
+}
+
+// Step adds a new named step to the chain, which makes a request with the given method and url, customised using builders.
+func (c *RequestChain) Step(name string, method, url string, builders ...RequestBuilder) *RequestChain {
+	c.steps = append(c.steps, chainStep{name: name, method: method, url: url, builders: builders})
+	return c
+}
+
+// WithResponseHandler adds a handler that's invoked between every pair of steps, with the response of
+// the previous step and the (fully built) request of the next one, so that it could, for example, extract
+// headers or substitute urls. If the handler fails, the chain stops.
+func (c *RequestChain) WithResponseHandler(fn func(prev *http.Response, next *http.Request) error) *RequestChain {
+	c.handlers = append(c.handlers, fn)
+	return c
+}
+
+// Run executes all the steps, in order, using fn and returns their responses. It fails t and stops
+// at the first step that fails. The bodies of the returned responses are buffered and could be read again.
+func (c *RequestChain) Run(t TestingT, fn ExecFn) []*http.Response {
+	t.Helper()
+
+	var responses []*http.Response
+	var bodies [][]byte
+	for i, step := range c.steps {
+		var builders = append([]RequestBuilder(nil), step.builders...)
+		if i > 0 {
+			var prev, body = responses[i-1], bodies[i-1]
+			builders = append(builders, func(next *http.Request) error {
+				for _, handler := range c.handlers {
+					prev.Body = ioutil.NopCloser(bytes.NewReader(body))
+					if err := handler(prev, next); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		}
+
+		var response *http.Response
+		var body []byte
+		fn.MakeRequest(Using(step.method, step.url, nil), builders...).ExpectIt(t, func(r *http.Response) (err error) {
+			response = r
+			body, err = ioutil.ReadAll(r.Body)
+			return err
+		})
+
+		if response == nil { // MakeRequest(...) failed and reported it to t
+			t.Errorf("httpx: chain: step '%s' failed", step.name)
+			t.FailNow()
+			break
+		}
+		responses, bodies = append(responses, response), append(bodies, body)
+	}
+
+	for i, response := range responses {
+		response.Body = ioutil.NopCloser(bytes.NewReader(bodies[i]))
+	}
+	return responses
+}
@@ -0,0 +1,65 @@
+package httpx_test
+
+import (
+	"errors"
+	. "go.riyazali.net/httpx"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestRequestChain(t *testing.T) {
+	var handler = func(writer http.ResponseWriter, request *http.Request) {
+		switch {
+		case request.Method == http.MethodPost && request.URL.Path == "/users":
+			writer.Header().Set("Location", "/users/42")
+			writer.WriteHeader(http.StatusCreated)
+		case request.URL.Path == "/users/42":
+			_, _ = io.WriteString(writer, request.Method+" user 42")
+		default:
+			writer.WriteHeader(http.StatusNotFound)
+		}
+	}
+
+	var location = func(prev *http.Response, next *http.Request) error {
+		if l := prev.Header.Get("Location"); l != "" {
+			next.URL.Path = l
+		}
+		return nil
+	}
+
+	t.Run("should run all steps with handler", func(t *testing.T) {
+		r := make(reporter)
+		var responses = new(RequestChain).
+			Step("create", http.MethodPost, "http://example.com/users").
+			Step("fetch", http.MethodGet, "http://example.com/placeholder").
+			Step("delete", http.MethodDelete, "http://example.com/placeholder").
+			WithResponseHandler(location).
+			Run(r, handlerExec(handler))
+
+		assert(t, 0 == r["Errorf"], "Errorf must not be called")
+		assert(t, len(responses) == 3, "must return all responses")
+		assert(t, responses[0].StatusCode == http.StatusCreated, "must execute first step")
+
+		var body, _ = ioutil.ReadAll(responses[1].Body)
+		assert(t, string(body) == "GET user 42", "must apply handler to next request, got: %s", body)
+		assert(t, responses[2].StatusCode == http.StatusNotFound, "must pass only previous response to handler")
+	})
+
+	t.Run("should stop at failing step", func(t *testing.T) {
+		r := make(reporter)
+		var responses = new(RequestChain).
+			Step("create", http.MethodPost, "http://example.com/users").
+			Step("fetch", http.MethodGet, "http://example.com/users/42").
+			Step("delete", http.MethodDelete, "http://example.com/users/42").
+			WithResponseHandler(func(prev *http.Response, next *http.Request) error {
+				return errors.New("boom")
+			}).
+			Run(r, handlerExec(handler))
+
+		assert(t, 0 < r["Errorf"], "Errorf must be called")
+		assert(t, 0 < r["FailNow"], "FailNow must be called")
+		assert(t, len(responses) == 1, "must return responses of steps executed so far")
+	})
+}
@@ -0,0 +1,171 @@
+package httpx
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ConcurrentAssertable holds an Assertable for each of the requests executed by ExecFn.Concurrent(...)
+type ConcurrentAssertable []Assertable
+
+// Concurrent builds a request using the factory and builders, and then executes n copies (cloned using
+// http.Request.Clone(...)) of it simultaneously, each in its own goroutine. Use it to stress test the
+// concurrency safety of a handler or an endpoint.
+//
+//  WithHandler(handler).Concurrent(10, Post("/counter", nil)).ExpectAll(t, ToHaveStatus(http.StatusOK))
+//
+// Request body (if any) is read in memory once and every copy is sent with the same bytes.
+// It fails if n is less than 1.
+func (fn ExecFn) Concurrent(n int, factory RequestFactory, builders ...RequestBuilder) ConcurrentAssertable {
+	var err error
+	if n < 1 {
+		return ConcurrentAssertable{fail("httpx: invalid number of concurrent requests: %d", n)}
+	}
+
+	// build a prototype request which is later cloned for every execution
+	var prototype *http.Request
+	if prototype, err = factory(); err != nil {
+		return ConcurrentAssertable{fail("httpx: failed to create request: %v", err)}
+	}
+
+	for _, fn := range builders {
+		if err = fn(prototype); err != nil {
+			return ConcurrentAssertable{fail("httpx: builder: %v", err)}
+		}
+	}
+
+	var body []byte
+	var hasBody = prototype.Body != nil && prototype.Body != http.NoBody
+	if hasBody {
+		if body, err = ioutil.ReadAll(prototype.Body); err != nil {
+			return ConcurrentAssertable{fail("httpx: failed to read request body: %v", err)}
+		}
+		_ = prototype.Body.Close()
+	}
+
+	var assertables = make(ConcurrentAssertable, n)
+	var start = make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		var request = prototype.Clone(prototype.Context())
+		if hasBody {
+			request.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start // wait for all goroutines to be ready
+			assertables[i] = execute(fn, request)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	return assertables
+}
+
+// ExpectAll runs the assertions against every response and fails if any of them fails an assertion.
+func (c ConcurrentAssertable) ExpectAll(t TestingT, assertions ...Assertion) {
+	t.Helper()
+	for _, a := range c {
+		a(t, assertions...)
+	}
+}
+
+// ExpectNone runs the assertions against every response and fails if any of them passes an assertion.
+func (c ConcurrentAssertable) ExpectNone(t TestingT, assertions ...Assertion) {
+	t.Helper()
+	var negated = make([]Assertion, len(assertions))
+	for i, fn := range assertions {
+		negated[i] = not(fn)
+	}
+	c.ExpectAll(t, negated...)
+}
+
+// ExpectAllStatus returns a function that checks that every one of the assertables (like the ones returned by
+// ExecFn.Concurrent(...)) has the given status, and reports all the failures together in a single error.
+//
+//  ExpectAllStatus(http.StatusOK, WithHandler(handler).Concurrent(10, Get("/")))(t)
+func ExpectAllStatus(code int, assertables []Assertable) func(t TestingT) {
+	var status = func(response *http.Response) error {
+		if response.StatusCode != code {
+			return fmt.Errorf("status: returned status (%d) not equal to expected status (%d)", response.StatusCode, code)
+		}
+		return nil
+	}
+	return ExpectAllMatch([]Assertion{status}, assertables)
+}
+
+// ExpectAllMatch returns a function that runs the assertions against every one of the assertables,
+// and reports all the failures together in a single error. See ExpectAllStatus(...)
+func ExpectAllMatch(assertions []Assertion, assertables []Assertable) func(t TestingT) {
+	return func(t TestingT) {
+		t.Helper()
+
+		var report strings.Builder
+		var failed = 0
+		for i, a := range assertables {
+			var c collector
+			a(&c, assertions...)
+			if len(c) > 0 {
+				failed++
+				_, _ = fmt.Fprintf(&report, "\n  #%d: %s", i, strings.Join(c, "; "))
+			}
+		}
+
+		if failed > 0 {
+			t.Errorf("httpx: %d of %d responses failed:%s", failed, len(assertables), report.String())
+		}
+	}
+}
+
+// collector is a TestingT that collects all the reported errors
+type collector []string
+
+func (c *collector) Errorf(format string, args ...interface{}) {
+	*c = append(*c, fmt.Sprintf(format, args...))
+}
+
+func (c *collector) FailNow() {}
+func (c *collector) Helper()  {}
+
+// execute executes the request and returns an Assertable which can be invoked multiple times
+func execute(fn ExecFn, request *http.Request) Assertable {
+	var start = time.Now()
+	var response, err = fn(request)
+	if err != nil {
+		return fail("httpx: failed to execute request: %v", err)
+	}
+	var trace = tracerOf(response) // set if the request was executed by a traced ExecFn
+	if trace != nil {
+		trace.start = start
+	}
+	defer response.Body.Close()
+
+	var body []byte
+	if body, err = ioutil.ReadAll(response.Body); err != nil {
+		return fail("httpx: failed to read body into buffer: %v", err)
+	}
+
+	return func(t TestingT, assertions ...Assertion) {
+		t.Helper()
+		expect(t, response, body, trace, assertions)
+	}
+}
+
+// not returns an Assertion that fails if the given assertion passes
+func not(fn Assertion) Assertion {
+	return func(response *http.Response) error {
+		if fn(response) == nil {
+			return errors.New("assertion expected to fail but passed")
+		}
+		return nil
+	}
+}
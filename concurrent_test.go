@@ -0,0 +1,118 @@
+package httpx_test
+
+import (
+	"errors"
+	"fmt"
+	. "go.riyazali.net/httpx"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestExecFn_Concurrent(t *testing.T) {
+	var mu sync.Mutex
+	var counter = 0
+	var handler = func(writer http.ResponseWriter, request *http.Request) {
+		var body []byte
+		if request.Body != nil {
+			body, _ = ioutil.ReadAll(request.Body)
+		}
+		mu.Lock()
+		counter++
+		mu.Unlock()
+		_, _ = fmt.Fprintf(writer, "%s", body)
+	}
+
+	var echoes = func(want string) Assertion {
+		return func(response *http.Response) error {
+			var body, _ = ioutil.ReadAll(response.Body)
+			if string(body) != want {
+				return errors.New("unexpected body")
+			}
+			return nil
+		}
+	}
+
+	t.Run("should execute all requests", func(t *testing.T) {
+		r := make(reporter)
+		var results = handlerExec(handler).Concurrent(10, Post("http://example.com", strings.NewReader("hello")))
+		assert(t, len(results) == 10, "must return an Assertable for every request")
+		assert(t, counter == 10, "must execute every request")
+
+		results.ExpectAll(r, echoes("hello"), echoes("hello"))
+		assert(t, 0 == r["Errorf"], "Errorf must not be called")
+
+		results.ExpectNone(r, echoes("world"))
+		assert(t, 0 == r["Errorf"], "Errorf must not be called")
+	})
+
+	t.Run("should fail if any response fails", func(t *testing.T) {
+		r := make(reporter)
+		handlerExec(handler).Concurrent(5, Get("http://example.com")).ExpectAll(r, echoes("hello"))
+		assert(t, 5 == r["Errorf"], "Errorf must be called for every response")
+	})
+
+	t.Run("should fail if any response passes with ExpectNone", func(t *testing.T) {
+		r := make(reporter)
+		handlerExec(handler).Concurrent(5, Get("http://example.com")).ExpectNone(r, echoes(""))
+		assert(t, 5 == r["Errorf"], "Errorf must be called for every response")
+	})
+
+	t.Run("should fail if builder returns error", func(t *testing.T) {
+		r := make(reporter)
+		handlerExec(handler).Concurrent(5, Get("http://example.com"), func(*http.Request) error {
+			return errors.New("test")
+		}).ExpectAll(r)
+		assert(t, 1 == r["Errorf"], "Errorf must be called exactly once")
+		assert(t, 1 == r["FailNow"], "FailNow must be called exactly once")
+	})
+
+	t.Run("should fail for invalid number of requests", func(t *testing.T) {
+		for _, n := range []int{0, -1} {
+			r := make(reporter)
+			handlerExec(handler).Concurrent(n, Get("http://example.com")).ExpectAll(r)
+			assert(t, 1 == r["Errorf"] && 1 == r["FailNow"], "must fail for %d requests", n)
+		}
+	})
+}
+
+func TestExpectAllStatus(t *testing.T) {
+	var mu sync.Mutex
+	var counter = 0
+	var handler = func(writer http.ResponseWriter, request *http.Request) {
+		mu.Lock()
+		counter++
+		var n = counter
+		mu.Unlock()
+		if n%2 == 0 {
+			writer.WriteHeader(http.StatusTooManyRequests)
+		}
+	}
+
+	t.Run("should pass if all responses match", func(t *testing.T) {
+		r := make(reporter)
+		ExpectAllStatus(http.StatusOK, handlerExec(func(http.ResponseWriter, *http.Request) {}).Concurrent(5, Get("/")))(r)
+		assert(t, 0 == r["Errorf"], "Errorf must not be called")
+	})
+
+	t.Run("should report all failures at once", func(t *testing.T) {
+		r := make(reporter)
+		ExpectAllStatus(http.StatusOK, handlerExec(handler).Concurrent(6, Get("/")))(r)
+		assert(t, 1 == r["Errorf"], "Errorf must be called exactly once")
+	})
+
+	t.Run("should run arbitrary assertions", func(t *testing.T) {
+		var failing = func(*http.Response) error { return errors.New("boom") }
+		var passing = func(*http.Response) error { return nil }
+		var assertables = handlerExec(handler).Concurrent(3, Get("/"))
+
+		r := make(reporter)
+		ExpectAllMatch([]Assertion{passing}, assertables)(r)
+		assert(t, 0 == r["Errorf"], "Errorf must not be called")
+
+		ExpectAllMatch([]Assertion{passing, failing, failing}, assertables)(r)
+		assert(t, 1 == r["Errorf"], "Errorf must be called exactly once")
+	})
+}
@@ -0,0 +1,267 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// WithCookieJar returns an ExecFn that wraps fn and uses the given jar to persist cookies
+// across requests made through it. Before a request is executed, cookies from the jar
+// are attached to it and once a response is received, cookies set by it are stored back in the jar.
+//
+// This makes it possible to write multi-step tests (say, login and then access a protected resource)
+// even when the underlying ExecFn (like one returned by executors.WithHandler) has no notion of cookies.
+//
+//  var exec = WithHandler(handler).WithCookieJar(NewCookieJar())
+//  exec.MakeRequest(Post("http://example.com/login", body)).ExpectIt(t, ToHaveStatus(http.StatusOK))
+//  exec.MakeRequest(Get("http://example.com/me")).ExpectIt(t, ToHaveStatus(http.StatusOK))
+//
+// Requests with a relative url are keyed in the jar using request's Host (or localhost, if that's empty too).
+func (fn ExecFn) WithCookieJar(jar http.CookieJar) ExecFn {
+	return func(request *http.Request) (*http.Response, error) {
+		var u = jarUrl(request)
+		for _, cookie := range jar.Cookies(u) {
+			request.AddCookie(cookie)
+		}
+
+		var response, err = fn(request)
+		if err == nil && response != nil {
+			if cookies := response.Cookies(); len(cookies) > 0 {
+				jar.SetCookies(u, cookies)
+			}
+		}
+		return response, err
+	}
+}
+
+// NewCookieJar returns a new, empty http.CookieJar that is safe for concurrent use by multiple goroutines.
+// It's a convenience wrapper over cookiejar.New(...) to be used with ExecFn.WithCookieJar(...)
+func NewCookieJar() http.CookieJar {
+	var jar, _ = cookiejar.New(nil) // never returns an error
+	return jar
+}
+
+// jarUrl returns the url used to key cookies for the given request in a cookie jar
+func jarUrl(request *http.Request) *url.URL {
+	var u = *request.URL
+	if u.Scheme == "" {
+		u.Scheme = "http"
+	}
+	if u.Host == "" {
+		if u.Host = request.Host; u.Host == "" {
+			u.Host = "localhost"
+		}
+	}
+	return &u
+}
+
+// GlobalTimeout is a deadline shared by all requests made through an ExecFn returned by ExecFn.WithGlobalTimeout(...)
+type GlobalTimeout struct {
+	total    time.Duration
+	deadline time.Time
+}
+
+// Remaining returns the time left before the deadline expires, or zero if it has already expired.
+func (g *GlobalTimeout) Remaining() time.Duration {
+	if remaining := time.Until(g.deadline); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// WithGlobalTimeout returns an ExecFn that wraps fn and enforces a single deadline, (now + total), shared across
+// all requests made through it. This is handy for test suites that must finish within a given time budget.
+//
+// Any request in-flight when the deadline expires is cancelled, and all subsequent requests fail
+// with an error wrapping context.DeadlineExceeded without ever invoking fn.
+//
+//  var exec, timeout = WithDefaultClient().WithGlobalTimeout(30 * time.Second)
+func (fn ExecFn) WithGlobalTimeout(total time.Duration) (ExecFn, *GlobalTimeout) {
+	var timeout = &GlobalTimeout{total: total, deadline: time.Now().Add(total)}
+	return func(request *http.Request) (*http.Response, error) {
+		if timeout.Remaining() == 0 {
+			return nil, fmt.Errorf("global timeout of %s exceeded: %w", timeout.total, context.DeadlineExceeded)
+		}
+
+		var ctx, cancel = context.WithDeadline(request.Context(), timeout.deadline)
+		var response, err = fn(request.WithContext(ctx))
+		if err != nil || response == nil || response.Body == nil {
+			cancel()
+			return response, err
+		}
+
+		// defer cancellation until the body is closed, as the body might still be streaming in
+		response.Body = &cancelOnClose{ReadCloser: response.Body, cancel: cancel}
+		return response, nil
+	}, timeout
+}
+
+// cancelOnClose is an io.ReadCloser that cancels the associated context when closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
+
+// HeaderBodyTruncated is the header set on responses whose body was truncated by ExecFn.WithResponseSizeLimit(...)
+const HeaderBodyTruncated = "X-Httpx-Body-Truncated"
+
+// WithResponseSizeLimit returns an ExecFn that wraps fn and reads at most maxBytes from the response body.
+// This prevents assertions from hanging on never-ending (or very large) streaming responses.
+//
+// If the body had more than maxBytes, it's truncated and the response is marked with
+// HeaderBodyTruncated header (set to "true"). Use assertions.ExpectBodyNotTruncated() to check for that.
+//
+// A maxBytes of math.MaxInt64 means no limit, and a negative maxBytes fails every request.
+func (fn ExecFn) WithResponseSizeLimit(maxBytes int64) ExecFn {
+	return func(request *http.Request) (*http.Response, error) {
+		if maxBytes < 0 {
+			return nil, fmt.Errorf("invalid response size limit of %d bytes", maxBytes)
+		}
+		if maxBytes == math.MaxInt64 {
+			return fn(request) // no limit; also, reading one byte more than that would overflow
+		}
+
+		var response, err = fn(request)
+		if err != nil || response == nil || response.Body == nil {
+			return response, err
+		}
+		defer response.Body.Close()
+
+		// read one byte more than the limit to find out if the body has more data than allowed
+		var body []byte
+		if body, err = ioutil.ReadAll(io.LimitReader(response.Body, maxBytes+1)); err != nil {
+			return nil, err
+		}
+		if int64(len(body)) > maxBytes {
+			body = body[:maxBytes]
+			response.ContentLength = maxBytes
+			response.Header.Set(HeaderBodyTruncated, "true")
+		}
+		response.Body = ioutil.NopCloser(bytes.NewReader(body))
+		return response, nil
+	}
+}
+
+// WithBodyCloseCheck returns an ExecFn that wraps fn and fails t, at the end of the test, if the body of any response
+// received through it was never closed. MakeRequest(...) always closes the response body once all the assertions
+// have run, so this is mostly useful to catch leaked bodies when responses are consumed some other way.
+//
+//  var exec = WithDefaultClient().WithBodyCloseCheck(t)
+//
+// It requires t to support registering cleanup functions (like testing.T does since Go 1.14),
+// and every request made through it fails otherwise.
+func (fn ExecFn) WithBodyCloseCheck(t TestingT) ExecFn {
+	return func(request *http.Request) (*http.Response, error) {
+		var c, ok = t.(interface{ Cleanup(func()) })
+		if !ok {
+			return nil, fmt.Errorf("%T doesn't support Cleanup(...)", t)
+		}
+
+		var response, err = fn(request)
+		if err != nil || response == nil || response.Body == nil {
+			return response, err
+		}
+
+		var spy = &closeSpy{ReadCloser: response.Body}
+		response.Body = spy
+		c.Cleanup(func() {
+			if !spy.closed() {
+				t.Errorf("httpx: body of response to %s %s was never closed", request.Method, request.URL)
+			}
+		})
+		return response, nil
+	}
+}
+
+// closeSpy is an io.ReadCloser that records whether it was closed
+type closeSpy struct {
+	io.ReadCloser
+	mu   sync.Mutex
+	done bool
+}
+
+func (c *closeSpy) Close() error {
+	c.mu.Lock()
+	c.done = true
+	c.mu.Unlock()
+	return c.ReadCloser.Close()
+}
+
+func (c *closeSpy) closed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.done
+}
+
+// WithDynamicBaseURL returns an ExecFn that wraps fn and resolves the base url of every request at the time it's made.
+// This is useful in dynamic environments where the address of a service is only known through some sort of service discovery.
+//
+// The resolver is invoked with the request's context and the scheme and host (including the port) of the request's url
+// are replaced with those of the returned url, leaving the rest of it (path, query etc.) intact. The request's Host
+// is replaced too, unless it was explicitly set to something other than the url's host.
+// If the resolver fails (or returns an invalid url) the request fails with that error and fn is never invoked.
+//
+//  var exec = WithDefaultClient().WithDynamicBaseURL(func(ctx context.Context) (string, error) {
+//    return registry.Lookup(ctx, "users-service")
+//  })
+//  exec.MakeRequest(Get("/users/1")).ExpectIt(t, ToHaveStatus(http.StatusOK))
+func (fn ExecFn) WithDynamicBaseURL(resolver func(ctx context.Context) (string, error)) ExecFn {
+	return func(request *http.Request) (*http.Response, error) {
+		var base, err = resolver(request.Context())
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve base url: %w", err)
+		}
+
+		var u *url.URL
+		if u, err = url.Parse(base); err != nil {
+			return nil, fmt.Errorf("failed to parse resolved base url: %w", err)
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("resolved base url (%s) must have a scheme and a host", base)
+		}
+
+		var r = request.Clone(request.Context())
+		if r.Host == "" || r.Host == r.URL.Host {
+			r.Host = u.Host // keep a Host explicitly set on the request (say, using builders.WithHost(...))
+		}
+		r.URL.Scheme, r.URL.Host = u.Scheme, u.Host
+		return fn(r)
+	}
+}
+
+// WithMiddleware returns an ExecFn that wraps fn with the given http.RoundTripper middleware, allowing any existing
+// client-side middleware (for logging, auth, metrics etc.) to be used with httpx as-is. The first middleware is the
+// outermost one, ie. it sees the request first and the response last.
+//
+//  var exec = WithDefaultClient().WithMiddleware(logging, authenticate)
+//
+// Unlike an http.Client, the composed round tripper is invoked directly, so requests with relative urls
+// (like those sent to executors.WithHandler(...)) keep working and redirects are left to fn.
+func (fn ExecFn) WithMiddleware(middleware ...func(http.RoundTripper) http.RoundTripper) ExecFn {
+	var rt http.RoundTripper = roundTripperFunc(fn)
+	for i := len(middleware) - 1; i >= 0; i-- {
+		rt = middleware[i](rt)
+	}
+	return rt.RoundTrip
+}
+
+// roundTripperFunc adapts a function to the http.RoundTripper interface
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (fn roundTripperFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return fn(request)
+}
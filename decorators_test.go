@@ -0,0 +1,299 @@
+package httpx_test
+
+import (
+	"context"
+	"errors"
+	. "go.riyazali.net/httpx"
+	. "go.riyazali.net/httpx/builders"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// helper function to build an ExecFn that invokes the given handler
+func handlerExec(handler http.HandlerFunc) ExecFn {
+	return func(request *http.Request) (*http.Response, error) {
+		var recorder = httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder.Result(), nil
+	}
+}
+
+func TestExecFn_WithCookieJar(t *testing.T) {
+	var handler = func(writer http.ResponseWriter, request *http.Request) {
+		switch request.URL.Path {
+		case "/login":
+			http.SetCookie(writer, &http.Cookie{Name: "session", Value: "s3cr3t", Path: "/"})
+		case "/me":
+			if c, err := request.Cookie("session"); err != nil || c.Value != "s3cr3t" {
+				writer.WriteHeader(http.StatusUnauthorized)
+			}
+		}
+	}
+
+	var status = func(s int) Assertion {
+		return func(response *http.Response) error {
+			if response.StatusCode != s {
+				return errors.New("unexpected status")
+			}
+			return nil
+		}
+	}
+
+	t.Run("should propagate cookies across requests", func(t *testing.T) {
+		r := make(reporter)
+		var exec = handlerExec(handler).WithCookieJar(NewCookieJar())
+		exec.MakeRequest(Get("http://example.com/login")).ExpectIt(r)
+		exec.MakeRequest(Get("http://example.com/me")).ExpectIt(r, status(http.StatusOK))
+		assert(t, 0 == r["Errorf"], "Errorf must not be called")
+	})
+
+	t.Run("should work with relative urls", func(t *testing.T) {
+		r := make(reporter)
+		var exec = handlerExec(handler).WithCookieJar(NewCookieJar())
+		exec.MakeRequest(Get("/login")).ExpectIt(r)
+		exec.MakeRequest(Get("/me")).ExpectIt(r, status(http.StatusOK))
+		assert(t, 0 == r["Errorf"], "Errorf must not be called")
+	})
+
+	t.Run("should not share cookies without a jar", func(t *testing.T) {
+		r := make(reporter)
+		var exec = handlerExec(handler)
+		exec.MakeRequest(Get("http://example.com/login")).ExpectIt(r)
+		exec.MakeRequest(Get("http://example.com/me")).ExpectIt(r, status(http.StatusOK))
+		assert(t, 1 == r["Errorf"], "Errorf must be called exactly once")
+	})
+}
+
+func TestExecFn_WithGlobalTimeout(t *testing.T) {
+	var handler = func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path == "/slow" {
+			select {
+			case <-request.Context().Done():
+				writer.WriteHeader(http.StatusGatewayTimeout)
+			case <-time.After(time.Second):
+			}
+		}
+	}
+
+	var exec, timeout = handlerExec(handler).WithGlobalTimeout(50 * time.Millisecond)
+	assert(t, timeout.Remaining() > 0 && timeout.Remaining() <= 50*time.Millisecond, "must report remaining time")
+
+	t.Run("should execute requests before deadline", func(t *testing.T) {
+		r := make(reporter)
+		exec.MakeRequest(Get("http://example.com/fast")).ExpectIt(r)
+		assert(t, 0 == r["Errorf"], "Errorf must not be called")
+	})
+
+	t.Run("should cancel in-flight request on deadline", func(t *testing.T) {
+		r := make(reporter)
+		exec.MakeRequest(Get("http://example.com/slow")).ExpectIt(r, func(response *http.Response) error {
+			if response.StatusCode != http.StatusGatewayTimeout {
+				return errors.New("request must be cancelled")
+			}
+			return nil
+		})
+		assert(t, 0 == r["Errorf"], "Errorf must not be called")
+		assert(t, timeout.Remaining() == 0, "must not have any remaining time")
+	})
+
+	t.Run("should fail requests after deadline", func(t *testing.T) {
+		r := make(reporter)
+		exec.MakeRequest(Get("http://example.com/fast")).ExpectIt(r)
+		assert(t, 1 == r["Errorf"], "Errorf must be called exactly once")
+		assert(t, 1 == r["FailNow"], "FailNow must be called exactly once")
+	})
+}
+
+func TestExecFn_WithResponseSizeLimit(t *testing.T) {
+	var handler = func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte("hello world"))
+	}
+
+	var body = func(want string, truncated bool) Assertion {
+		return func(response *http.Response) error {
+			var b, _ = ioutil.ReadAll(response.Body)
+			if string(b) != want {
+				return errors.New("unexpected body")
+			}
+			if (response.Header.Get(HeaderBodyTruncated) == "true") != truncated {
+				return errors.New("unexpected truncation marker")
+			}
+			return nil
+		}
+	}
+
+	t.Run("should truncate body over the limit", func(t *testing.T) {
+		r := make(reporter)
+		handlerExec(handler).WithResponseSizeLimit(5).MakeRequest(Get("http://example.com")).ExpectIt(r, body("hello", true))
+		assert(t, 0 == r["Errorf"], "Errorf must not be called")
+	})
+
+	t.Run("should not truncate body within the limit", func(t *testing.T) {
+		r := make(reporter)
+		handlerExec(handler).WithResponseSizeLimit(11).MakeRequest(Get("http://example.com")).ExpectIt(r, body("hello world", false))
+		assert(t, 0 == r["Errorf"], "Errorf must not be called")
+	})
+
+	t.Run("should not limit body with max limit", func(t *testing.T) {
+		r := make(reporter)
+		handlerExec(handler).WithResponseSizeLimit(math.MaxInt64).MakeRequest(Get("http://example.com")).ExpectIt(r, body("hello world", false))
+		assert(t, 0 == r["Errorf"], "Errorf must not be called")
+	})
+
+	t.Run("should fail for negative limit", func(t *testing.T) {
+		var _, err = handlerExec(handler).WithResponseSizeLimit(-1)(httptest.NewRequest(http.MethodGet, "/", nil))
+		assert(t, err != nil, "must return an error")
+	})
+}
+
+func TestExecFn_WithDynamicBaseURL(t *testing.T) {
+	var handler = func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("X-Url", request.URL.String())
+	}
+
+	var url = func(want string) Assertion {
+		return func(response *http.Response) error {
+			if got := response.Header.Get("X-Url"); got != want {
+				return errors.New("unexpected url: " + got)
+			}
+			return nil
+		}
+	}
+
+	t.Run("should replace scheme and host", func(t *testing.T) {
+		r := make(reporter)
+		var calls = 0
+		var exec = handlerExec(handler).WithDynamicBaseURL(func(ctx context.Context) (string, error) {
+			calls++
+			return "https://users.internal:8443", nil
+		})
+		exec.MakeRequest(Get("http://localhost/users/1?expand=true")).ExpectIt(r, url("https://users.internal:8443/users/1?expand=true"))
+		exec.MakeRequest(Get("/users/2")).ExpectIt(r, url("https://users.internal:8443/users/2"))
+		assert(t, 0 == r["Errorf"], "Errorf must not be called")
+		assert(t, 2 == calls, "resolver must be called for every request")
+	})
+
+	t.Run("should retain explicitly set host", func(t *testing.T) {
+		var hosts []string
+		var exec = handlerExec(func(writer http.ResponseWriter, request *http.Request) {
+			hosts = append(hosts, request.Host)
+		}).WithDynamicBaseURL(func(ctx context.Context) (string, error) {
+			return "https://users.internal:8443", nil
+		})
+
+		r := make(reporter)
+		exec.MakeRequest(Get("http://localhost/users/1"), WithHost("api.example.com")).ExpectIt(r)
+		exec.MakeRequest(Get("http://localhost/users/1"), WithHostOverride("admin.example.com")).ExpectIt(r)
+		exec.MakeRequest(Get("http://localhost/users/1")).ExpectIt(r)
+		assert(t, 0 == r["Errorf"], "Errorf must not be called")
+		assert(t, len(hosts) == 3 && hosts[0] == "api.example.com" && hosts[1] == "admin.example.com" && hosts[2] == "users.internal:8443",
+			"must only replace host that wasn't set explicitly, got: %v", hosts)
+	})
+
+	t.Run("should fail if resolution fails", func(t *testing.T) {
+		r := make(reporter)
+		var exec = handlerExec(handler).WithDynamicBaseURL(func(ctx context.Context) (string, error) {
+			return "", errors.New("service not found")
+		})
+		exec.MakeRequest(Get("/users/1")).ExpectIt(r)
+		assert(t, 1 == r["Errorf"], "Errorf must be called")
+	})
+
+	t.Run("should fail for invalid base url", func(t *testing.T) {
+		r := make(reporter)
+		var exec = handlerExec(handler).WithDynamicBaseURL(func(ctx context.Context) (string, error) {
+			return "users.internal", nil
+		})
+		exec.MakeRequest(Get("/users/1")).ExpectIt(r)
+		assert(t, 1 == r["Errorf"], "Errorf must be called")
+	})
+}
+
+func TestExecFn_WithMiddleware(t *testing.T) {
+	var calls []string
+	var middleware = func(name string) func(http.RoundTripper) http.RoundTripper {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return roundTripper(func(request *http.Request) (*http.Response, error) {
+				calls = append(calls, name)
+				request.Header.Add("X-Middleware", name)
+				return next.RoundTrip(request)
+			})
+		}
+	}
+
+	var handler = func(writer http.ResponseWriter, request *http.Request) {
+		calls = append(calls, "handler")
+		writer.Header()["X-Middleware"] = request.Header["X-Middleware"]
+	}
+
+	t.Run("should invoke middleware in order", func(t *testing.T) {
+		calls = nil
+		var response, err = handlerExec(handler).WithMiddleware(middleware("first"), middleware("second"))(httptest.NewRequest(http.MethodGet, "/", nil))
+		assert(t, err == nil, "must not return an error")
+		assert(t, len(calls) == 3 && calls[0] == "first" && calls[1] == "second" && calls[2] == "handler", "must invoke middleware outermost first")
+		assert(t, len(response.Header["X-Middleware"]) == 2, "must pass modified request to inner ExecFn")
+	})
+
+	t.Run("should invoke fn without middleware", func(t *testing.T) {
+		calls = nil
+		var _, err = handlerExec(handler).WithMiddleware()(httptest.NewRequest(http.MethodGet, "/", nil))
+		assert(t, err == nil && len(calls) == 1, "must invoke inner ExecFn")
+	})
+
+	t.Run("should propagate errors", func(t *testing.T) {
+		var failing = func(http.RoundTripper) http.RoundTripper {
+			return roundTripper(func(*http.Request) (*http.Response, error) { return nil, errors.New("failed") })
+		}
+		var _, err = handlerExec(handler).WithMiddleware(failing)(httptest.NewRequest(http.MethodGet, "/", nil))
+		assert(t, err != nil, "must return error from middleware")
+	})
+}
+
+// roundTripper adapts a function to the http.RoundTripper interface
+type roundTripper func(*http.Request) (*http.Response, error)
+
+func (fn roundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
+	return fn(request)
+}
+
+// cleanupT is a TestingT that supports registering cleanup functions
+type cleanupT struct {
+	reporter
+	cleanups []func()
+}
+
+func (c *cleanupT) Cleanup(fn func()) { c.cleanups = append(c.cleanups, fn) }
+
+func (c *cleanupT) finish() {
+	for _, fn := range c.cleanups {
+		fn()
+	}
+}
+
+func TestExecFn_WithBodyCloseCheck(t *testing.T) {
+	var handler = func(writer http.ResponseWriter, request *http.Request) { _, _ = writer.Write([]byte("hello")) }
+
+	t.Run("should pass if framework closes body", func(t *testing.T) {
+		var ct = &cleanupT{reporter: make(reporter)}
+		handlerExec(handler).WithBodyCloseCheck(ct).MakeRequest(Get("/")).ExpectIt(ct)
+		ct.finish()
+		assert(t, 0 == ct.reporter["Errorf"], "Errorf must not be called")
+	})
+
+	t.Run("should fail if body is never closed", func(t *testing.T) {
+		var ct = &cleanupT{reporter: make(reporter)}
+		var _, err = handlerExec(handler).WithBodyCloseCheck(ct)(httptest.NewRequest(http.MethodGet, "/", nil))
+		assert(t, err == nil, "must not return an error")
+		ct.finish()
+		assert(t, 1 == ct.reporter["Errorf"], "must report unclosed body at cleanup")
+	})
+
+	t.Run("should fail without cleanup support", func(t *testing.T) {
+		var _, err = handlerExec(handler).WithBodyCloseCheck(make(reporter))(httptest.NewRequest(http.MethodGet, "/", nil))
+		assert(t, err != nil, "must fail if t doesn't support cleanup")
+	})
+}
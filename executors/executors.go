@@ -2,9 +2,20 @@
 package executors
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
 	"go.riyazali.net/httpx"
+	"io"
+	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/http/httptrace"
+	"os"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -15,6 +26,9 @@ func WithDefaultClient() httpx.ExecFn {
 
 // WithClient returns an ExecFn that wraps an http.Client.
 // Use opts to customise the http.Client.
+//
+// Options that customise the client's transport (like WithMockDNS(...) or WithTLSMinVersion(...)) only work with an
+// *http.Transport, and have no effect if another option sets some other http.RoundTripper as the client's transport.
 func WithClient(opts ...func(*http.Client)) httpx.ExecFn {
 	var client = &http.Client{}
 	for _, fn := range opts {
@@ -54,6 +68,306 @@ func WithNoRedirect() func(*http.Client) {
 	}
 }
 
+// WithMaxRedirects configures the client to follow at most n redirects, failing the request if it's redirected more.
+// The default is to follow at most 10 redirects.
+func WithMaxRedirects(n int) func(*http.Client) {
+	return func(client *http.Client) {
+		client.CheckRedirect = func(_ *http.Request, via []*http.Request) error {
+			if len(via) > n {
+				return fmt.Errorf("stopped after %d redirects", n)
+			}
+			return nil
+		}
+	}
+}
+
+// WithDisabledCompression disables transparent compression on the client's transport.
+// By default, the transport requests gzip compressed responses and decompresses them before returning.
+// With compression disabled, the raw response bytes along with the Content-Encoding header are visible to assertions.
+func WithDisabledCompression() func(*http.Client) {
+	return func(client *http.Client) {
+		if t := transport(client); t != nil {
+			t.DisableCompression = true
+		}
+	}
+}
+
+// WithStreamingResponse configures the client for testing streaming endpoints (say, server-sent events or chunked responses).
+// It waits at most headerTimeout for response headers, removes the overall client timeout (which would otherwise
+// include the time spent reading the body) and disables transparent decompression so that bytes are delivered as they arrive.
+//
+// Note that MakeRequest(...) reads the complete response body before running any assertions. To read a stream
+// incrementally, invoke the ExecFn directly; in that case the caller must close response.Body explicitly.
+//
+//    var response, err = WithClient(WithStreamingResponse(5 * time.Second))(request)
+//    defer response.Body.Close()
+func WithStreamingResponse(headerTimeout time.Duration) func(*http.Client) {
+	return func(client *http.Client) {
+		client.Timeout = 0
+		if t := transport(client); t != nil {
+			t.ResponseHeaderTimeout = headerTimeout
+			t.DisableCompression = true
+		}
+	}
+}
+
+// WithTLSMinVersion sets the minimum TLS version (like tls.VersionTLS12) the client would negotiate
+func WithTLSMinVersion(version uint16) func(*http.Client) {
+	return func(client *http.Client) {
+		if c := tlsConfig(client); c != nil {
+			c.MinVersion = version
+		}
+	}
+}
+
+// WithTLSMaxVersion sets the maximum TLS version (like tls.VersionTLS13) the client would negotiate
+func WithTLSMaxVersion(version uint16) func(*http.Client) {
+	return func(client *http.Client) {
+		if c := tlsConfig(client); c != nil {
+			c.MaxVersion = version
+		}
+	}
+}
+
+// WithTLSVersion restricts the client to negotiate TLS versions between min and max (both inclusive)
+func WithTLSVersion(min, max uint16) func(*http.Client) {
+	return func(client *http.Client) {
+		WithTLSMinVersion(min)(client)
+		WithTLSMaxVersion(max)(client)
+	}
+}
+
+// WithTLS12Only restricts the client to only use TLS 1.2
+func WithTLS12Only() func(*http.Client) {
+	return WithTLSVersion(tls.VersionTLS12, tls.VersionTLS12)
+}
+
+// WithTLS13Only restricts the client to only use TLS 1.3
+func WithTLS13Only() func(*http.Client) {
+	return WithTLSVersion(tls.VersionTLS13, tls.VersionTLS13)
+}
+
+// WithInsecureSkipVerify disables verification of the server's certificate chain and host name, for testing against
+// servers with self-signed certificates. As this makes the client accept any certificate, a warning is printed
+// to os.Stderr every time it's used. Prefer WithCustomCA(...) to trust a specific certificate instead.
+func WithInsecureSkipVerify() func(*http.Client) {
+	return func(client *http.Client) {
+		if c := tlsConfig(client); c != nil {
+			_, _ = fmt.Fprintln(os.Stderr, "httpx: WARNING: TLS certificate verification is disabled (WithInsecureSkipVerify), "+
+				"do not use it for anything but local testing")
+			c.InsecureSkipVerify = true
+		}
+	}
+}
+
+// WithCustomCA configures the client to trust certificates signed by the given PEM encoded certificate(s),
+// in addition to the system's root certificates. Use it to test against environments (like staging)
+// that use certificates issued by a private CA. If certPEM has no valid certificate, verification would
+// fail for servers that aren't trusted by the system.
+func WithCustomCA(certPEM []byte) func(*http.Client) {
+	return func(client *http.Client) {
+		var c = tlsConfig(client)
+		if c == nil {
+			return
+		}
+
+		var pool, err = x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		pool.AppendCertsFromPEM(certPEM)
+		c.RootCAs = pool
+	}
+}
+
+// WithMockDNS configures the client's transport to resolve hostnames using the given map, instead of the DNS.
+// Entries in the map could either map a host to a "host:port" pair (like "my-service.internal" to "127.0.0.1:8080")
+// or a host to another host, in which case the port of the original address is retained.
+// Hostnames that are not found in the map are resolved and dialed normally.
+func WithMockDNS(hosts map[string]string) func(*http.Client) {
+	return func(client *http.Client) {
+		var t = transport(client)
+		if t == nil {
+			return
+		}
+		var dial = t.DialContext
+		if dial == nil {
+			dial = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+		}
+
+		t.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			var host, port, err = net.SplitHostPort(addr)
+			if err != nil {
+				return dial(ctx, network, addr)
+			}
+			if target, ok := hosts[host]; ok {
+				if _, _, err := net.SplitHostPort(target); err != nil {
+					target = net.JoinHostPort(target, port) // target is just a host; keep the original port
+				}
+				addr = target
+			}
+			return dial(ctx, network, addr)
+		}
+	}
+}
+
+// WithKeepAlive configures the client to reuse connections across requests (which is the default), and records
+// whether a response was received over a reused connection in httpx.ResponseInfo (see httpx.ResponseInfoOf(...)),
+// for use with assertions.ExpectConnectionReuse(...)
+func WithKeepAlive() func(*http.Client) { return keepAlive(true) }
+
+// WithNoKeepAlive configures the client to use a new connection for every request.
+// See WithKeepAlive() for more details.
+func WithNoKeepAlive() func(*http.Client) { return keepAlive(false) }
+
+func keepAlive(enabled bool) func(*http.Client) {
+	return func(client *http.Client) {
+		if t := transport(client); t != nil {
+			t.DisableKeepAlives = !enabled
+		}
+		record(client, func(info *httpx.ResponseInfo, conn httptrace.GotConnInfo) {
+			var reused = conn.Reused
+			info.ConnectionReused = &reused
+		})
+	}
+}
+
+// WithHeaderOrder configures the client to record the order in which response headers were received on the wire.
+// Go's http.Header is a map and doesn't retain that order, so it's recorded in httpx.ResponseInfo
+// (see httpx.ResponseInfoOf(...)) instead, for use with assertions.ExpectHeaderOrdering(...)
+//
+// The order is captured by inspecting the raw bytes read from the connection and so only works with
+// plain-text HTTP/1.x connections (and not with TLS or HTTP/2). Apply this option after WithTransport(...)
+// as that replaces the client's transport altogether.
+func WithHeaderOrder() func(*http.Client) {
+	return func(client *http.Client) {
+		var t = transport(client)
+		if t == nil {
+			return
+		}
+		var dial = t.DialContext
+		if dial == nil {
+			dial = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+		}
+
+		t.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			var conn, err = dial(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			return &headerOrderConn{Conn: conn}, nil
+		}
+		record(client, func(info *httpx.ResponseInfo, conn httptrace.GotConnInfo) {
+			if c, ok := conn.Conn.(*headerOrderConn); ok {
+				info.HeaderOrder = c.order()
+			}
+		})
+	}
+}
+
+// recordingTransport is an http.RoundTripper that wraps the client's transport and invokes the recorders with
+// the connection every response was received on, so that they could record details about it in httpx.ResponseInfo.
+// The info is passed along in the context of the request, which the transport sets as the response's request.
+type recordingTransport struct {
+	next      http.RoundTripper
+	recorders []func(*httpx.ResponseInfo, httptrace.GotConnInfo)
+}
+
+func (t *recordingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	var conn httptrace.GotConnInfo
+	var info = &httpx.ResponseInfo{}
+	var ctx = httptrace.WithClientTrace(request.Context(), &httptrace.ClientTrace{GotConn: func(i httptrace.GotConnInfo) { conn = i }})
+	var response, err = t.next.RoundTrip(request.WithContext(httpx.WithResponseInfo(ctx, info)))
+	if err != nil {
+		return response, err
+	}
+	for _, record := range t.recorders {
+		record(info, conn)
+	}
+	return response, nil
+}
+
+// record adds the recorder to the client's transport, wrapping it in a recordingTransport if required.
+// Any http.RoundTripper could be wrapped, but info is only recorded if it supports httptrace (like *http.Transport does).
+func record(client *http.Client, recorder func(*httpx.ResponseInfo, httptrace.GotConnInfo)) {
+	_ = transport(client) // replace a missing (or the shared default) transport with a clone first
+	var rt, ok = client.Transport.(*recordingTransport)
+	if !ok {
+		rt = &recordingTransport{next: client.Transport}
+		client.Transport = rt
+	}
+	rt.recorders = append(rt.recorders, recorder)
+}
+
+// headerOrderConn is a net.Conn that parses the header names (in order) of the responses read from it.
+// Parsing starts when a request is written to the connection and stops at the end of response's header block.
+type headerOrderConn struct {
+	net.Conn
+
+	mu      sync.Mutex
+	parsing bool     // set while parsing the header block
+	line    []byte   // current (partial) line
+	status  string   // status line of the response being parsed
+	names   []string // names of the headers parsed so far
+	last    []string // names of the headers of the last response
+}
+
+func (c *headerOrderConn) Write(b []byte) (int, error) {
+	c.mu.Lock()
+	if !c.parsing {
+		c.parsing, c.line, c.status, c.names = true, nil, "", nil
+	}
+	c.mu.Unlock()
+	return c.Conn.Write(b)
+}
+
+func (c *headerOrderConn) Read(b []byte) (int, error) {
+	var n, err = c.Conn.Read(b)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := 0; i < n && c.parsing; i++ {
+		if b[i] != '\n' {
+			c.line = append(c.line, b[i])
+			continue
+		}
+
+		var line = strings.TrimRight(string(c.line), "\r")
+		c.line = c.line[:0]
+		switch {
+		case c.status == "":
+			c.status = line
+		case line == "":
+			if strings.HasPrefix(c.status, "HTTP/1.1 1") || strings.HasPrefix(c.status, "HTTP/1.0 1") {
+				c.status, c.names = "", nil // informational (1xx) response; the final one is yet to come
+				continue
+			}
+			c.parsing, c.last = false, c.names
+		default:
+			if colon := strings.IndexByte(line, ':'); colon > 0 {
+				c.names = append(c.names, http.CanonicalHeaderKey(strings.TrimSpace(line[:colon])))
+			}
+		}
+	}
+	return n, err
+}
+
+// order returns the names of the headers of the last response, in order, with duplicates removed
+func (c *headerOrderConn) order() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var seen = make(map[string]bool, len(c.last))
+	var names = make([]string, 0, len(c.last))
+	for _, name := range c.last {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
 // WithHandler wraps the given http.Handler and returns an ExecFn that invokes
 // the handler on request and return the response. This ExecFn doesn't need to make network round-trip
 // and can be used to implement unit tests for http endpoints in your application.
@@ -70,3 +384,93 @@ func WithHandler(handler http.Handler) httpx.ExecFn {
 func WithHandlerFn(fn http.HandlerFunc) httpx.ExecFn {
 	return WithHandler(fn)
 }
+
+// HealthCheckExecFn makes GET requests to targetURL every interval until it responds with 200 OK, or until timeout elapses.
+// It returns nil on the first 200 response, or an error describing the last status (or error) received if it times out.
+// It's meant to be used in TestMain(...) to wait for the services under test to be ready, such as,
+//
+//    func TestMain(m *testing.M) {
+//        if err := HealthCheckExecFn("http://localhost:8080/health", 30*time.Second, 500*time.Millisecond); err != nil {
+//            log.Fatal(err)
+//        }
+//        os.Exit(m.Run())
+//    }
+func HealthCheckExecFn(targetURL string, timeout time.Duration, interval time.Duration) error {
+	var ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var client = &http.Client{}
+	var last = "no request made"
+	for {
+		var request, err = http.NewRequestWithContext(ctx, http.MethodGet, targetURL, nil)
+		if err != nil {
+			return fmt.Errorf("health check: failed to create request: %v", err)
+		}
+
+		var response *http.Response
+		if response, err = client.Do(request); err == nil {
+			_, _ = io.Copy(ioutil.Discard, response.Body)
+			_ = response.Body.Close()
+			if response.StatusCode == http.StatusOK {
+				return nil
+			}
+			last = fmt.Sprintf("last status: %d", response.StatusCode)
+		} else if ctx.Err() == nil {
+			last = fmt.Sprintf("last error: %v", err)
+		}
+
+		var timer = time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("health check: %s not healthy after %s, %s", targetURL, timeout, last)
+		case <-timer.C:
+		}
+	}
+}
+
+// transport returns the *http.Transport used by the client so that it can be customised.
+// If the transport is wrapped by an option that records details of responses (like WithHeaderOrder()), the wrapped transport is returned.
+// If the client doesn't have one (or uses the shared http.DefaultTransport) a clone of
+// http.DefaultTransport is set on the client and returned.
+//
+// It returns nil if the client uses some other http.RoundTripper (say, one that adds auth or tracing), as that can't
+// be customised and replacing it would drop whatever it does. Options that customise the transport skip it then.
+func transport(client *http.Client) *http.Transport {
+	var rt, recording = client.Transport.(*recordingTransport)
+	var current = client.Transport
+	if recording {
+		current = rt.next
+	}
+
+	switch t := current.(type) {
+	case *http.Transport:
+		if t != http.DefaultTransport {
+			return t
+		}
+	case nil:
+	default:
+		return nil
+	}
+
+	var t = http.DefaultTransport.(*http.Transport).Clone()
+	if recording {
+		rt.next = t
+	} else {
+		client.Transport = t
+	}
+	return t
+}
+
+// tlsConfig returns the tls.Config used by the client's transport, creating one if not set.
+// It returns nil if the transport can't be customised (see transport(...))
+func tlsConfig(client *http.Client) *tls.Config {
+	var t = transport(client)
+	if t == nil {
+		return nil
+	}
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{}
+	}
+	return t.TLSClientConfig
+}
@@ -1,10 +1,24 @@
 package executors_test
 
 import (
+	"bufio"
+	"compress/gzip"
+	"crypto/tls"
+	"encoding/pem"
+	"go.riyazali.net/httpx"
 	. "go.riyazali.net/httpx/executors"
+	"io"
+	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"os"
 	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -16,6 +30,14 @@ func assert(t *testing.T, cond bool, msg string, args ...interface{}) {
 	}
 }
 
+func require(t *testing.T, cond bool, msg string, args ...interface{}) {
+	t.Helper()
+	if !cond {
+		t.Errorf(msg, args...)
+		t.FailNow()
+	}
+}
+
 func TestCustomClient(t *testing.T) {
 	var jar, _ = cookiejar.New(&cookiejar.Options{})
 
@@ -53,3 +75,357 @@ func TestWithHandler(t *testing.T) {
 	_, _ = WithHandlerFn(handler)(&http.Request{})
 	assert(t, called, "handler must be invoked")
 }
+
+func TestWithMockDNS(t *testing.T) {
+	var server = httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("X-Host", request.Host)
+	}))
+	defer server.Close()
+
+	var u, _ = url.Parse(server.URL)
+	var hostOnly, port, _ = net.SplitHostPort(u.Host)
+
+	var exec = WithClient(WithMockDNS(map[string]string{
+		"my-service.internal":    u.Host,
+		"other-service.internal": hostOnly,
+	}))
+
+	t.Run("should resolve mapped host:port", func(t *testing.T) {
+		var response, err = exec(newRequest(t, "http://my-service.internal/"))
+		require(t, err == nil, "request must succeed: %v", err)
+		defer response.Body.Close()
+		assert(t, response.Header.Get("X-Host") == "my-service.internal", "must retain original host header")
+	})
+
+	t.Run("should retain port when mapped to host", func(t *testing.T) {
+		var response, err = exec(newRequest(t, "http://other-service.internal:"+port+"/"))
+		require(t, err == nil, "request must succeed: %v", err)
+		_ = response.Body.Close()
+	})
+
+	t.Run("should dial unmapped hosts normally", func(t *testing.T) {
+		var response, err = exec(newRequest(t, server.URL))
+		require(t, err == nil, "request must succeed: %v", err)
+		_ = response.Body.Close()
+	})
+
+	t.Run("should not modify default transport", func(t *testing.T) {
+		WithClient(WithTransport(http.DefaultTransport.(*http.Transport)), WithMockDNS(nil), func(client *http.Client) {
+			assert(t, client.Transport != http.DefaultTransport, "must clone default transport")
+		})
+	})
+}
+
+func newRequest(t *testing.T, u string) *http.Request {
+	t.Helper()
+	var request, err = http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	return request
+}
+
+func TestWithDisabledCompression(t *testing.T) {
+	var server = httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("X-Accept-Encoding", request.Header.Get("Accept-Encoding"))
+		writer.Header().Set("Content-Encoding", "gzip")
+		var gz = gzip.NewWriter(writer)
+		_, _ = gz.Write([]byte("hello world"))
+		_ = gz.Close()
+	}))
+	defer server.Close()
+
+	var response, err = WithClient(WithDisabledCompression())(newRequest(t, server.URL))
+	require(t, err == nil, "request must succeed: %v", err)
+	defer response.Body.Close()
+
+	var body, _ = ioutil.ReadAll(response.Body)
+	assert(t, response.Header.Get("X-Accept-Encoding") == "", "must not request compressed response")
+	assert(t, response.Header.Get("Content-Encoding") == "gzip", "must retain Content-Encoding header")
+	assert(t, len(body) > 2 && body[0] == 0x1f && body[1] == 0x8b, "must return raw compressed bytes")
+}
+
+func TestWithStreamingResponse(t *testing.T) {
+	t.Run("should read body for longer than client timeout", func(t *testing.T) {
+		var next = make(chan struct{})
+		var server = httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			writer.Header().Set("X-Accept-Encoding", request.Header.Get("Accept-Encoding"))
+			_, _ = io.WriteString(writer, "first\n")
+			writer.(http.Flusher).Flush()
+			<-next
+			_, _ = io.WriteString(writer, "second\n")
+		}))
+		defer server.Close()
+		defer close(next)
+
+		var timeout = 100 * time.Millisecond
+		var response, err = WithClient(WithTimeout(timeout), WithStreamingResponse(time.Second))(newRequest(t, server.URL))
+		require(t, err == nil, "request must succeed: %v", err)
+		defer response.Body.Close()
+		assert(t, response.Header.Get("X-Accept-Encoding") == "", "must disable transparent compression")
+
+		var reader = bufio.NewReader(response.Body)
+		var line, _ = reader.ReadString('\n')
+		assert(t, line == "first\n", "must deliver first chunk before handler finishes writing")
+
+		time.Sleep(2 * timeout) // would've cancelled the body read if the client timeout was still set
+		next <- struct{}{}
+		line, err = reader.ReadString('\n')
+		assert(t, err == nil && line == "second\n", "must deliver remaining bytes after client timeout, got: %q (%v)", line, err)
+	})
+
+	t.Run("should time out waiting for headers", func(t *testing.T) {
+		var done = make(chan struct{})
+		var server = httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			select { // don't block forever if headers are awaited without a timeout
+			case <-done:
+			case <-time.After(time.Second):
+			}
+		}))
+		defer server.Close()
+		defer close(done)
+
+		var _, err = WithClient(WithStreamingResponse(50 * time.Millisecond))(newRequest(t, server.URL))
+		assert(t, err != nil && strings.Contains(err.Error(), "timeout awaiting response headers"), "must time out waiting for headers, got: %v", err)
+	})
+}
+
+func TestWithTLSVersion(t *testing.T) {
+	var server = httptest.NewUnstartedServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	server.TLS = &tls.Config{MinVersion: tls.VersionTLS12, MaxVersion: tls.VersionTLS12}
+	server.StartTLS()
+	defer server.Close()
+
+	var tlsVersion = func(opts ...func(*http.Client)) (uint16, error) {
+		var client = server.Client() // trusts server's certificate
+		var transport = client.Transport.(*http.Transport).Clone()
+		var response, err = WithClient(append([]func(*http.Client){WithTransport(transport)}, opts...)...)(newRequest(t, server.URL))
+		if err != nil {
+			return 0, err
+		}
+		defer response.Body.Close()
+		return response.TLS.Version, nil
+	}
+
+	var version, err = tlsVersion(WithTLS12Only())
+	assert(t, err == nil && version == tls.VersionTLS12, "must negotiate TLS 1.2")
+
+	_, err = tlsVersion(WithTLS13Only())
+	assert(t, err != nil, "must fail to negotiate TLS 1.3 with a TLS 1.2 only server")
+
+	version, err = tlsVersion(WithTLSMinVersion(tls.VersionTLS10), WithTLSMaxVersion(tls.VersionTLS13))
+	assert(t, err == nil && version == tls.VersionTLS12, "must negotiate version within range")
+}
+
+func TestWithHeaderOrder(t *testing.T) {
+	// go's http server always writes headers in sorted order, so use a raw one to control the order on the wire
+	var listener, err = net.Listen("tcp", "127.0.0.1:0")
+	require(t, err == nil, "must listen: %v", err)
+	defer listener.Close()
+
+	go func() {
+		for {
+			var conn, err = listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				var reader = bufio.NewReader(conn)
+				for {
+					var request, err = http.ReadRequest(reader)
+					if err != nil {
+						return
+					}
+					_, _ = io.WriteString(conn, "HTTP/1.1 200 OK\r\n"+
+						"Zeta: 1\r\nAlpha: 2\r\nx-custom: "+request.URL.Path+"\r\nAlpha: 3\r\nContent-Length: 2\r\n\r\nok")
+				}
+			}(conn)
+		}
+	}()
+
+	var exec = WithClient(WithHeaderOrder())
+	for _, path := range []string{"/first", "/second"} { // second request reuses the connection
+		var response, err = exec(newRequest(t, "http://"+listener.Addr().String()+path))
+		require(t, err == nil, "request must succeed: %v", err)
+
+		var body, _ = ioutil.ReadAll(response.Body)
+		_ = response.Body.Close()
+		assert(t, string(body) == "ok", "must not alter the body")
+		assert(t, response.Header.Get("X-Custom") == path, "must not alter other headers")
+		assert(t, len(response.Header) == 4, "must not add headers to response, got: %v", response.Header)
+
+		var info = httpx.ResponseInfoOf(response)
+		require(t, info != nil, "must record response info")
+		assert(t, strings.Join(info.HeaderOrder, ", ") == "Zeta, Alpha, X-Custom, Content-Length",
+			"must record order of headers, got: %v", info.HeaderOrder)
+	}
+}
+
+func TestWithHeaderOrder_WithOtherOptions(t *testing.T) {
+	var server = httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("X-Accept-Encoding", request.Header.Get("Accept-Encoding"))
+	}))
+	defer server.Close()
+
+	var response, err = WithClient(WithHeaderOrder(), WithDisabledCompression())(newRequest(t, server.URL))
+	require(t, err == nil, "request must succeed: %v", err)
+	defer response.Body.Close()
+
+	assert(t, response.Header.Get("X-Accept-Encoding") == "", "must apply options on the wrapped transport")
+	var info = httpx.ResponseInfoOf(response)
+	assert(t, info != nil && len(info.HeaderOrder) > 0, "must retain header order recording")
+}
+
+// customTransport is an http.RoundTripper that isn't an *http.Transport
+type customTransport struct {
+	http.RoundTripper
+	calls int32
+}
+
+func (c *customTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&c.calls, 1)
+	return c.RoundTripper.RoundTrip(request)
+}
+
+func TestWithCustomRoundTripper(t *testing.T) {
+	var server = httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+
+	var custom = &customTransport{RoundTripper: http.DefaultTransport}
+	var exec = WithClient(func(client *http.Client) { client.Transport = custom },
+		WithHeaderOrder(), WithKeepAlive(), WithMockDNS(nil), WithTLS12Only(), WithCustomCA(nil), WithDisabledCompression())
+
+	var response, err = exec(newRequest(t, server.URL))
+	require(t, err == nil, "request must succeed: %v", err)
+	_ = response.Body.Close()
+
+	assert(t, atomic.LoadInt32(&custom.calls) == 1, "must use the custom transport")
+	var info = httpx.ResponseInfoOf(response)
+	assert(t, info != nil && info.ConnectionReused != nil, "must still record details supported by the custom transport")
+	assert(t, info != nil && info.HeaderOrder == nil, "must skip options that need an *http.Transport")
+}
+
+func TestHealthCheckExecFn(t *testing.T) {
+	t.Run("should wait until healthy", func(t *testing.T) {
+		var calls int32
+		var server = httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if atomic.AddInt32(&calls, 1) < 3 {
+				writer.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer server.Close()
+
+		var err = HealthCheckExecFn(server.URL, time.Second, 10*time.Millisecond)
+		assert(t, err == nil, "must succeed once healthy: %v", err)
+		assert(t, atomic.LoadInt32(&calls) == 3, "must poll until healthy")
+	})
+
+	t.Run("should time out with last status", func(t *testing.T) {
+		var server = httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		var err = HealthCheckExecFn(server.URL, 50*time.Millisecond, 10*time.Millisecond)
+		assert(t, err != nil && strings.Contains(err.Error(), "503"), "must fail with last status, got: %v", err)
+	})
+
+	t.Run("should time out with last error", func(t *testing.T) {
+		var server = httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+		server.Close() // nothing listens on the address anymore
+
+		var err = HealthCheckExecFn(server.URL, 50*time.Millisecond, 10*time.Millisecond)
+		assert(t, err != nil && strings.Contains(err.Error(), "last error"), "must fail with last error, got: %v", err)
+	})
+}
+
+func TestWithKeepAlive(t *testing.T) {
+	var server = httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = io.WriteString(writer, "ok")
+	}))
+	defer server.Close()
+
+	var reused = func(exec httpx.ExecFn) []string {
+		var values []string
+		for i := 0; i < 2; i++ {
+			var response, err = exec(newRequest(t, server.URL))
+			require(t, err == nil, "request must succeed: %v", err)
+			_, _ = io.Copy(ioutil.Discard, response.Body)
+			_ = response.Body.Close()
+			var info = httpx.ResponseInfoOf(response)
+			require(t, info != nil && info.ConnectionReused != nil, "must record connection reuse")
+			values = append(values, strconv.FormatBool(*info.ConnectionReused))
+		}
+		return values
+	}
+
+	var values = reused(WithClient(WithKeepAlive()))
+	assert(t, values[0] == "false" && values[1] == "true", "must reuse connection, got: %v", values)
+
+	values = reused(WithClient(WithNoKeepAlive()))
+	assert(t, values[0] == "false" && values[1] == "false", "must not reuse connection, got: %v", values)
+
+	t.Run("should work with other recording options", func(t *testing.T) {
+		var response, err = WithClient(WithKeepAlive(), WithHeaderOrder())(newRequest(t, server.URL))
+		require(t, err == nil, "request must succeed: %v", err)
+		defer response.Body.Close()
+		var info = httpx.ResponseInfoOf(response)
+		require(t, info != nil, "must record response info")
+		assert(t, info.ConnectionReused != nil, "must record connection reuse")
+		assert(t, len(info.HeaderOrder) > 0, "must record header order")
+	})
+}
+
+func TestWithInsecureSkipVerify(t *testing.T) {
+	var server = httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+
+	var _, err = WithClient()(newRequest(t, server.URL))
+	assert(t, err != nil, "must fail to verify self-signed certificate")
+
+	// capture warning written to stderr
+	var r, w, _ = os.Pipe()
+	var stderr = os.Stderr
+	os.Stderr = w
+	var exec = WithClient(WithInsecureSkipVerify())
+	os.Stderr = stderr
+	_ = w.Close()
+	var warning, _ = ioutil.ReadAll(r)
+
+	var response *http.Response
+	response, err = exec(newRequest(t, server.URL))
+	require(t, err == nil, "request must succeed: %v", err)
+	_ = response.Body.Close()
+	assert(t, strings.Contains(string(warning), "WARNING"), "must print a warning, got: %s", warning)
+}
+
+func TestWithCustomCA(t *testing.T) {
+	var server = httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+
+	var ca = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	var response, err = WithClient(WithCustomCA(ca))(newRequest(t, server.URL))
+	require(t, err == nil, "request must succeed: %v", err)
+	_ = response.Body.Close()
+
+	_, err = WithClient(WithCustomCA([]byte("not a certificate")))(newRequest(t, server.URL))
+	assert(t, err != nil, "must fail to verify with invalid ca")
+}
+
+func TestWithMaxRedirects(t *testing.T) {
+	var mux = http.NewServeMux()
+	mux.Handle("/a", http.RedirectHandler("/b", http.StatusFound))
+	mux.Handle("/b", http.RedirectHandler("/c", http.StatusFound))
+	mux.HandleFunc("/c", func(http.ResponseWriter, *http.Request) {})
+	var server = httptest.NewServer(mux)
+	defer server.Close()
+
+	var response, err = WithClient(WithMaxRedirects(2))(newRequest(t, server.URL+"/a"))
+	require(t, err == nil, "request must succeed: %v", err)
+	_ = response.Body.Close()
+	assert(t, response.Request.URL.Path == "/c", "must follow redirects")
+
+	_, err = WithClient(WithMaxRedirects(1))(newRequest(t, server.URL+"/a"))
+	assert(t, err != nil, "must fail if redirected more than allowed")
+}
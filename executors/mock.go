@@ -0,0 +1,161 @@
+package executors
+
+import (
+	"bytes"
+	"fmt"
+	"go.riyazali.net/httpx"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// MockBuilder provides a fluent api to build an httpx.ExecFn that responds to requests with canned responses,
+// without making any network call. Rules are evaluated in the order they are defined and the first one that
+// matches the request is used to respond to it.
+//
+//  var exec = NewMockBuilder().
+//    WhenGET("/users/1").Return(http.StatusOK, []byte(`{"id": 1}`)).
+//    WhenPOST("/users").WithHeader("Content-Type", "application/json").Return(http.StatusCreated, nil).
+//    WhenAny().Return(http.StatusNotFound, nil).
+//    Build()
+type MockBuilder struct {
+	rules []*MockRule
+}
+
+// MockRule is a single rule defined using a MockBuilder. It matches requests and defines the response sent for them.
+type MockRule struct {
+	builder *MockBuilder
+
+	method  string // empty matches any method
+	path    string // empty matches any path
+	headers http.Header
+
+	status int
+	body   []byte
+}
+
+// NewMockBuilder returns a new MockBuilder with no rules
+func NewMockBuilder() *MockBuilder { return &MockBuilder{} }
+
+// When adds a new rule matching requests with the given method and path.
+// An empty method (or path) matches requests with any method (or path).
+func (b *MockBuilder) When(method, path string) *MockRule {
+	var rule = &MockRule{builder: b, method: strings.ToUpper(method), path: path, headers: http.Header{}, status: http.StatusOK}
+	b.rules = append(b.rules, rule)
+	return rule
+}
+
+// WhenGET adds a new rule matching GET requests for the given path
+func (b *MockBuilder) WhenGET(path string) *MockRule { return b.When(http.MethodGet, path) }
+
+// WhenPOST adds a new rule matching POST requests for the given path
+func (b *MockBuilder) WhenPOST(path string) *MockRule { return b.When(http.MethodPost, path) }
+
+// WhenPUT adds a new rule matching PUT requests for the given path
+func (b *MockBuilder) WhenPUT(path string) *MockRule { return b.When(http.MethodPut, path) }
+
+// WhenDELETE adds a new rule matching DELETE requests for the given path
+func (b *MockBuilder) WhenDELETE(path string) *MockRule { return b.When(http.MethodDelete, path) }
+
+// WhenAny adds a new rule matching all requests. Use it as the last rule to define a catch-all response.
+func (b *MockBuilder) WhenAny() *MockRule { return b.When("", "") }
+
+// WithHeader restricts the rule to requests that have the given header set to value
+func (r *MockRule) WithHeader(key, value string) *MockRule {
+	r.headers.Add(key, value)
+	return r
+}
+
+// Return sets the response sent for requests matching the rule and returns the MockBuilder to continue defining other rules.
+func (r *MockRule) Return(status int, body []byte) *MockBuilder {
+	r.status, r.body = status, body
+	return r.builder
+}
+
+// Build returns an httpx.ExecFn that responds based on the rules defined so far.
+// A request that doesn't match any rule fails with an error.
+//
+// Rules are validated when building and if any rule can never be matched (because an earlier rule matches all the
+// requests it does, like a catch-all defined before specific rules), every request made through the returned
+// ExecFn fails with an error describing it.
+func (b *MockBuilder) Build() httpx.ExecFn {
+	var rules = append([]*MockRule(nil), b.rules...)
+
+	for i, rule := range rules {
+		for j := 0; j < i; j++ {
+			if rules[j].covers(rule) {
+				var err = fmt.Errorf("mock: rule #%d (%s) is unreachable as it's shadowed by rule #%d (%s)", i, rule, j, rules[j])
+				return func(*http.Request) (*http.Response, error) { return nil, err }
+			}
+		}
+	}
+
+	return func(request *http.Request) (*http.Response, error) {
+		for _, rule := range rules {
+			if rule.matches(request) {
+				return &http.Response{
+					Status:        fmt.Sprintf("%d %s", rule.status, http.StatusText(rule.status)),
+					StatusCode:    rule.status,
+					Proto:         "HTTP/1.1",
+					ProtoMajor:    1,
+					ProtoMinor:    1,
+					Header:        http.Header{},
+					Body:          ioutil.NopCloser(bytes.NewReader(rule.body)),
+					ContentLength: int64(len(rule.body)),
+					Request:       request,
+				}, nil
+			}
+		}
+		return nil, fmt.Errorf("mock: no rule matches request %s %s", request.Method, request.URL.Path)
+	}
+}
+
+// matches reports whether the request matches the rule
+func (r *MockRule) matches(request *http.Request) bool {
+	if (r.method != "" && r.method != request.Method) || (r.path != "" && r.path != request.URL.Path) {
+		return false
+	}
+	for key, values := range r.headers {
+		for _, value := range values {
+			if !contains(request.Header[key], value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// covers reports whether r matches every request that other does
+func (r *MockRule) covers(other *MockRule) bool {
+	if (r.method != "" && r.method != other.method) || (r.path != "" && r.path != other.path) {
+		return false
+	}
+	for key, values := range r.headers {
+		for _, value := range values {
+			if !contains(other.headers[key], value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (r *MockRule) String() string {
+	var method, path = r.method, r.path
+	if method == "" {
+		method = "*"
+	}
+	if path == "" {
+		path = "*"
+	}
+	return method + " " + path
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
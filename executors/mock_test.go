@@ -0,0 +1,93 @@
+package executors_test
+
+import (
+	. "go.riyazali.net/httpx/executors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMockBuilder(t *testing.T) {
+	var exec = NewMockBuilder().
+		WhenGET("/users/1").Return(http.StatusOK, []byte(`{"id": 1}`)).
+		WhenPOST("/users").WithHeader("Content-Type", "application/json").Return(http.StatusCreated, nil).
+		When("", "/health").Return(http.StatusNoContent, nil).
+		WhenAny().Return(http.StatusNotFound, nil).
+		Build()
+
+	var do = func(method, path string, kv ...string) (int, string) {
+		var request, _ = http.NewRequest(method, "http://example.com"+path, nil)
+		for i := 0; i+1 < len(kv); i += 2 {
+			request.Header.Set(kv[i], kv[i+1])
+		}
+		var response, err = exec(request)
+		require(t, err == nil, "request must succeed: %v", err)
+		defer response.Body.Close()
+		var body, _ = ioutil.ReadAll(response.Body)
+		return response.StatusCode, string(body)
+	}
+
+	var status, body = do(http.MethodGet, "/users/1")
+	assert(t, status == http.StatusOK && body == `{"id": 1}`, "must respond with matching rule")
+
+	status, _ = do(http.MethodPost, "/users", "Content-Type", "application/json")
+	assert(t, status == http.StatusCreated, "must match rule with header")
+
+	status, _ = do(http.MethodPost, "/users")
+	assert(t, status == http.StatusNotFound, "must not match rule if header is missing")
+
+	status, _ = do(http.MethodHead, "/health")
+	assert(t, status == http.StatusNoContent, "must match rule for any method")
+
+	status, _ = do(http.MethodDelete, "/users/1")
+	assert(t, status == http.StatusNotFound, "must fallback to catch-all rule")
+}
+
+func TestMockBuilder_NoMatch(t *testing.T) {
+	var exec = NewMockBuilder().WhenGET("/users").Return(http.StatusOK, nil).Build()
+	var _, err = exec(newRequest(t, "http://example.com/orders"))
+	assert(t, err != nil, "must fail if no rule matches")
+}
+
+func TestMockBuilder_Unreachable(t *testing.T) {
+	var cases = map[string]struct {
+		builder *MockBuilder
+		method  string // method of the shadowed rule
+	}{
+		"catch-all before specific rule": {NewMockBuilder().
+			WhenAny().Return(http.StatusNotFound, nil).
+			WhenGET("/users").Return(http.StatusOK, nil), http.MethodGet},
+		"duplicate rule": {NewMockBuilder().
+			WhenGET("/users").Return(http.StatusOK, nil).
+			WhenGET("/users").Return(http.StatusNoContent, nil), http.MethodGet},
+		"rule without header before rule with header": {NewMockBuilder().
+			WhenPOST("/users").Return(http.StatusBadRequest, nil).
+			WhenPOST("/users").WithHeader("Content-Type", "application/json").Return(http.StatusCreated, nil), http.MethodPost},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var request, _ = http.NewRequest(c.method, "http://example.com/users", nil)
+			request.Header.Set("Content-Type", "application/json")
+			var _, err = c.builder.Build()(request)
+			assert(t, err != nil && strings.Contains(err.Error(), "unreachable"), "must fail for unreachable rule, got: %v", err)
+		})
+	}
+
+	t.Run("should allow specific rule before generic one", func(t *testing.T) {
+		var exec = NewMockBuilder().
+			WhenPOST("/users").WithHeader("Content-Type", "application/json").Return(http.StatusCreated, nil).
+			WhenPOST("/users").Return(http.StatusBadRequest, nil).
+			Build()
+
+		for contentType, want := range map[string]int{"application/json": http.StatusCreated, "text/plain": http.StatusBadRequest} {
+			var request, _ = http.NewRequest(http.MethodPost, "http://example.com/users", nil)
+			request.Header.Set("Content-Type", contentType)
+			var response, err = exec(request)
+			require(t, err == nil, "request must succeed: %v", err)
+			_ = response.Body.Close()
+			assert(t, response.StatusCode == want, "must match rule for %s, got: %d", contentType, response.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,43 @@
+//go:build go1.18
+// +build go1.18
+
+package httpx
+
+import (
+	"net/http"
+	"testing"
+)
+
+// FuzzRequestBuilder integrates httpx with Go's native fuzzing engine.
+//
+// It registers the given seeds with f's corpus and then fuzzes the target. For every input generated by
+// the fuzzing engine, each of the builder factories is called with that input and the resulting builders
+// are composed together into a single RequestBuilder, which is then passed on to target.
+//
+//  func FuzzCreateUser(f *testing.F) {
+//    FuzzRequestBuilder(f, [][]byte{[]byte("alice")},
+//      func(t *testing.T, builder RequestBuilder) {
+//        WithHandler(handler).MakeRequest(Post("/users", nil), builder).ExpectIt(t, ToHaveStatus(http.StatusCreated))
+//      },
+//      func(data []byte) RequestBuilder { return WithHeader("X-User-Name", string(data)) },
+//    )
+//  }
+//
+// This file is only built with Go 1.18 (or above) which introduced native fuzzing support.
+func FuzzRequestBuilder(f *testing.F, seeds [][]byte, target func(*testing.T, RequestBuilder), builders ...func([]byte) RequestBuilder) {
+	f.Helper()
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		target(t, func(request *http.Request) error {
+			for _, factory := range builders {
+				if err := factory(data)(request); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
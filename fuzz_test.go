@@ -0,0 +1,37 @@
+//go:build go1.18
+// +build go1.18
+
+package httpx_test
+
+import (
+	"errors"
+	. "go.riyazali.net/httpx"
+	"net/http"
+	"testing"
+)
+
+func FuzzRequestBuilderSeeds(f *testing.F) {
+	var handler = func(writer http.ResponseWriter, request *http.Request) {
+		if request.Header.Get("X-Name") != request.Header.Get("X-Other") {
+			writer.WriteHeader(http.StatusBadRequest)
+		}
+	}
+
+	var header = func(name string) func([]byte) RequestBuilder {
+		return func(data []byte) RequestBuilder {
+			return func(request *http.Request) error { request.Header.Set(name, string(data)); return nil }
+		}
+	}
+
+	FuzzRequestBuilder(f, [][]byte{[]byte("alice"), []byte("bob")},
+		func(t *testing.T, builder RequestBuilder) {
+			handlerExec(handler).MakeRequest(Get("http://example.com"), builder).ExpectIt(t, func(response *http.Response) error {
+				if response.StatusCode != http.StatusOK {
+					return errors.New("every builder must receive the same input")
+				}
+				return nil
+			})
+		},
+		header("X-Name"), header("X-Other"),
+	)
+}
@@ -0,0 +1,22 @@
+package helpers
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"encoding/base64"
+	"fmt"
+)
+
+// SignCookie returns the signed form of a cookie's value, as value + "|" + signature, where signature is the
+// url-safe base64 encoded HMAC (using the given hash function and secret) of name + "=" + value.
+//
+// The package implementing the hash function (like crypto/sha256 for crypto.SHA256) must be linked into the binary.
+func SignCookie(name, value, secret string, hashFunc crypto.Hash) (string, error) {
+	if !hashFunc.Available() {
+		return "", fmt.Errorf("cookie: hash function (%d) is not available, make sure to import its package", hashFunc)
+	}
+
+	var mac = hmac.New(hashFunc.New, []byte(secret))
+	_, _ = mac.Write([]byte(name + "=" + value)) // never returns an error
+	return value + "|" + base64.URLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
@@ -0,0 +1,19 @@
+package helpers
+
+import (
+	"crypto"
+	_ "crypto/sha256"
+	"testing"
+)
+
+func TestSignCookie(t *testing.T) {
+	var signed, err = SignCookie("session", "user-1", "s3cr3t", crypto.SHA256)
+	assert(t, err == nil, "must not return error")
+	assert(t, signed == "user-1|2EjcDUlwcDLLRLLqz9s7hjOfxJWQcz9vyb9QwJpEInM=", "must sign name and value, got: %s", signed)
+
+	var other, _ = SignCookie("session", "user-1", "other", crypto.SHA256)
+	assert(t, signed != other, "must depend on secret")
+
+	_, err = SignCookie("session", "user-1", "s3cr3t", crypto.Hash(0))
+	assert(t, err != nil, "must fail for unavailable hash function")
+}
@@ -0,0 +1,170 @@
+package helpers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"go.riyazali.net/httpx"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// MockOAuthServer is a fake OAuth2 authorization server that supports the client_credentials
+// and authorization_code grants. Use it to test OAuth2 flows end-to-end without a real provider.
+//
+//  var server = NewMockOAuthServer(t, WithOAuthClient("id", "secret"))
+//  defer server.Close()
+//  server.SetAccessToken("test-token")
+//  // point the code under test to server.TokenURL() / server.AuthURL()
+type MockOAuthServer struct {
+	*httptest.Server
+	t httpx.TestingT
+
+	mu       sync.Mutex
+	clientID string
+	secret   string
+	token    string
+	expiry   time.Duration
+	codes    map[string]string // issued authorization codes mapped to their redirect uri
+}
+
+// OAuthOption customises a MockOAuthServer
+type OAuthOption func(*MockOAuthServer)
+
+// WithOAuthClient configures the client credentials accepted by the server. By default, any client is accepted.
+func WithOAuthClient(id, secret string) OAuthOption {
+	return func(s *MockOAuthServer) { s.clientID, s.secret = id, secret }
+}
+
+// NewMockOAuthServer starts and returns a new MockOAuthServer. Requests to unknown endpoints are reported as errors on t.
+// It is the caller's responsibility to Close() the server once done.
+func NewMockOAuthServer(t httpx.TestingT, opts ...OAuthOption) *MockOAuthServer {
+	var s = &MockOAuthServer{t: t, token: randomString(), expiry: time.Hour, codes: make(map[string]string)}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	var mux = http.NewServeMux()
+	mux.HandleFunc("/authorize", s.authorize)
+	mux.HandleFunc("/token", s.issueToken)
+	mux.HandleFunc("/", func(writer http.ResponseWriter, request *http.Request) {
+		s.t.Errorf("httpx: oauth: unexpected request to %s %s", request.Method, request.URL.Path)
+		writer.WriteHeader(http.StatusNotFound)
+	})
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// AuthURL returns the url of the authorization endpoint
+func (s *MockOAuthServer) AuthURL() string { return s.URL + "/authorize" }
+
+// TokenURL returns the url of the token endpoint
+func (s *MockOAuthServer) TokenURL() string { return s.URL + "/token" }
+
+// SetAccessToken sets the access token issued by the server (default: a random string)
+func (s *MockOAuthServer) SetAccessToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = token
+}
+
+// SetTokenExpiry sets the expiry reported for issued tokens (default: 1 hour)
+func (s *MockOAuthServer) SetTokenExpiry(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expiry = d
+}
+
+// authorize handles the authorization endpoint by redirecting back to redirect_uri with a new code
+func (s *MockOAuthServer) authorize(writer http.ResponseWriter, request *http.Request) {
+	var q = request.URL.Query()
+	var redirect, err = url.Parse(q.Get("redirect_uri"))
+	if err != nil || !redirect.IsAbs() {
+		oauthError(writer, http.StatusBadRequest, "invalid_request")
+		return
+	}
+	if q.Get("response_type") != "code" {
+		oauthError(writer, http.StatusBadRequest, "unsupported_response_type")
+		return
+	}
+	if s.clientID != "" && q.Get("client_id") != s.clientID {
+		oauthError(writer, http.StatusUnauthorized, "unauthorized_client")
+		return
+	}
+
+	var code = randomString()
+	s.mu.Lock()
+	s.codes[code] = redirect.String()
+	s.mu.Unlock()
+
+	var params = redirect.Query()
+	params.Set("code", code)
+	if state := q.Get("state"); state != "" {
+		params.Set("state", state)
+	}
+	redirect.RawQuery = params.Encode()
+	http.Redirect(writer, request, redirect.String(), http.StatusFound)
+}
+
+// issueToken handles the token endpoint
+func (s *MockOAuthServer) issueToken(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		oauthError(writer, http.StatusMethodNotAllowed, "invalid_request")
+		return
+	}
+	if err := request.ParseForm(); err != nil {
+		oauthError(writer, http.StatusBadRequest, "invalid_request")
+		return
+	}
+
+	// client can authenticate either using basic auth or form parameters
+	var id, secret, ok = request.BasicAuth()
+	if !ok {
+		id, secret = request.PostForm.Get("client_id"), request.PostForm.Get("client_secret")
+	}
+	if s.clientID != "" && (id != s.clientID || secret != s.secret) {
+		oauthError(writer, http.StatusUnauthorized, "invalid_client")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch request.PostForm.Get("grant_type") {
+	case "client_credentials":
+	case "authorization_code":
+		var code = request.PostForm.Get("code")
+		if redirect, ok := s.codes[code]; !ok || redirect != request.PostForm.Get("redirect_uri") {
+			oauthError(writer, http.StatusBadRequest, "invalid_grant")
+			return
+		}
+		delete(s.codes, code) // codes can only be used once
+	default:
+		oauthError(writer, http.StatusBadRequest, "unsupported_grant_type")
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	writer.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+		"access_token": s.token,
+		"token_type":   "Bearer",
+		"expires_in":   int64(s.expiry / time.Second),
+	})
+}
+
+// oauthError writes an OAuth2 error response
+func oauthError(writer http.ResponseWriter, status int, code string) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	_ = json.NewEncoder(writer).Encode(map[string]string{"error": code})
+}
+
+// randomString returns a random, hex-encoded string
+func randomString() string {
+	var b = make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,68 @@
+package helpers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMockOAuthServer(t *testing.T) {
+	var server = NewMockOAuthServer(t, WithOAuthClient("id", "secret"))
+	defer server.Close()
+
+	server.SetAccessToken("test-token")
+	server.SetTokenExpiry(time.Minute)
+
+	var token = func(form url.Values) (int, map[string]interface{}) {
+		var response, err = http.PostForm(server.TokenURL(), form)
+		if err != nil {
+			t.Fatalf("failed to request token: %v", err)
+		}
+		defer response.Body.Close()
+		var body map[string]interface{}
+		_ = json.NewDecoder(response.Body).Decode(&body)
+		return response.StatusCode, body
+	}
+
+	t.Run("client credentials", func(t *testing.T) {
+		var status, body = token(url.Values{"grant_type": {"client_credentials"}, "client_id": {"id"}, "client_secret": {"secret"}})
+		assert(t, status == http.StatusOK, "must issue token")
+		assert(t, body["access_token"] == "test-token" && body["token_type"] == "Bearer", "must return configured token")
+		assert(t, body["expires_in"] == float64(60), "must return configured expiry")
+
+		status, body = token(url.Values{"grant_type": {"client_credentials"}, "client_id": {"id"}, "client_secret": {"wrong"}})
+		assert(t, status == http.StatusUnauthorized && body["error"] == "invalid_client", "must reject invalid client")
+
+		status, body = token(url.Values{"grant_type": {"password"}, "client_id": {"id"}, "client_secret": {"secret"}})
+		assert(t, status == http.StatusBadRequest && body["error"] == "unsupported_grant_type", "must reject unsupported grant")
+	})
+
+	t.Run("authorization code", func(t *testing.T) {
+		var client = &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+		var response, err = client.Get(server.AuthURL() + "?" + url.Values{
+			"response_type": {"code"}, "client_id": {"id"}, "redirect_uri": {"https://app.example.com/cb"}, "state": {"xyz"},
+		}.Encode())
+		if err != nil {
+			t.Fatalf("failed to authorize: %v", err)
+		}
+		_ = response.Body.Close()
+
+		var location, _ = url.Parse(response.Header.Get("Location"))
+		assert(t, response.StatusCode == http.StatusFound, "must redirect")
+		assert(t, strings.HasPrefix(location.String(), "https://app.example.com/cb?"), "must redirect to redirect_uri")
+		assert(t, location.Query().Get("state") == "xyz", "must pass state back")
+
+		var form = url.Values{
+			"grant_type": {"authorization_code"}, "code": {location.Query().Get("code")},
+			"redirect_uri": {"https://app.example.com/cb"}, "client_id": {"id"}, "client_secret": {"secret"},
+		}
+		var status, body = token(form)
+		assert(t, status == http.StatusOK && body["access_token"] == "test-token", "must exchange code for token")
+
+		status, body = token(form)
+		assert(t, status == http.StatusBadRequest && body["error"] == "invalid_grant", "must not accept a code twice")
+	})
+}
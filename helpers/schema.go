@@ -0,0 +1,222 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+)
+
+// ValidateJSONSchema validates the json document against the given JSON Schema and returns
+// an error describing all the violations found, or nil if the document is valid.
+//
+// Only a commonly used subset of the specification is supported, namely: type, enum, const,
+// properties, required, additionalProperties, items, minItems, maxItems, minLength, maxLength,
+// pattern, minimum, maximum, exclusiveMinimum, exclusiveMaximum, allOf, anyOf, oneOf and not.
+// Any other keyword (like $schema, title or format) is ignored.
+func ValidateJSONSchema(schema, document []byte) error {
+	var s, doc interface{}
+	if err := json.Unmarshal(schema, &s); err != nil {
+		return fmt.Errorf("schema: failed to parse schema: %v", err)
+	}
+	if err := json.Unmarshal(document, &doc); err != nil {
+		return fmt.Errorf("schema: failed to parse document: %v", err)
+	}
+	return Multiple(validate(s, doc, "$")...)
+}
+
+// validate validates value v at the given path against schema s and returns all the violations found
+func validate(s interface{}, v interface{}, path string) (errs []error) {
+	var schema, ok = s.(map[string]interface{})
+	if !ok {
+		if b, isBool := s.(bool); isBool && !b {
+			return []error{fmt.Errorf("%s: no value is allowed", path)}
+		}
+		return nil // true, or an invalid schema, allows everything
+	}
+
+	var fail = func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %s", path, fmt.Sprintf(format, args...)))
+	}
+
+	if t, ok := schema["type"]; ok && !matchType(t, v) {
+		fail("expected type %v, got %s", t, typeOf(v))
+		return errs // other keywords make little sense if the type doesn't match
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		var found = false
+		for _, e := range enum {
+			if reflect.DeepEqual(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("value %v is not one of %v", v, enum)
+		}
+	}
+
+	if c, ok := schema["const"]; ok && !reflect.DeepEqual(c, v) {
+		fail("value %v is not equal to %v", v, c)
+	}
+
+	switch val := v.(type) {
+	case map[string]interface{}:
+		var properties, _ = schema["properties"].(map[string]interface{})
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, r := range required {
+				if name, ok := r.(string); ok {
+					if _, present := val[name]; !present {
+						fail("missing required property '%s'", name)
+					}
+				}
+			}
+		}
+
+		var keys = make([]string, 0, len(val))
+		for key := range val {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys) // report violations in a stable order
+
+		for _, key := range keys {
+			if ps, ok := properties[key]; ok {
+				errs = append(errs, validate(ps, val[key], path+"."+key)...)
+			} else if additional, ok := schema["additionalProperties"]; ok {
+				if b, isBool := additional.(bool); isBool && !b {
+					fail("additional property '%s' is not allowed", key)
+				} else {
+					errs = append(errs, validate(additional, val[key], path+"."+key)...)
+				}
+			}
+		}
+
+	case []interface{}:
+		if n, ok := number(schema["minItems"]); ok && float64(len(val)) < n {
+			fail("expected at least %v items, got %d", n, len(val))
+		}
+		if n, ok := number(schema["maxItems"]); ok && float64(len(val)) > n {
+			fail("expected at most %v items, got %d", n, len(val))
+		}
+		if items, ok := schema["items"]; ok {
+			for i, item := range val {
+				errs = append(errs, validate(items, item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+
+	case string:
+		var length = float64(len([]rune(val)))
+		if n, ok := number(schema["minLength"]); ok && length < n {
+			fail("expected length of at least %v, got %v", n, length)
+		}
+		if n, ok := number(schema["maxLength"]); ok && length > n {
+			fail("expected length of at most %v, got %v", n, length)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err != nil {
+				fail("invalid pattern '%s': %v", pattern, err)
+			} else if !re.MatchString(val) {
+				fail("value '%s' does not match pattern '%s'", val, pattern)
+			}
+		}
+
+	case float64:
+		if n, ok := number(schema["minimum"]); ok && val < n {
+			fail("value %v is less than minimum %v", val, n)
+		}
+		if n, ok := number(schema["maximum"]); ok && val > n {
+			fail("value %v is greater than maximum %v", val, n)
+		}
+		if n, ok := number(schema["exclusiveMinimum"]); ok && val <= n {
+			fail("value %v is not greater than %v", val, n)
+		}
+		if n, ok := number(schema["exclusiveMaximum"]); ok && val >= n {
+			fail("value %v is not less than %v", val, n)
+		}
+	}
+
+	if all, ok := schema["allOf"].([]interface{}); ok {
+		for _, sub := range all {
+			errs = append(errs, validate(sub, v, path)...)
+		}
+	}
+
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok {
+		var matched = false
+		for _, sub := range anyOf {
+			if len(validate(sub, v, path)) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("value does not match any of the schemas in anyOf")
+		}
+	}
+
+	if oneOf, ok := schema["oneOf"].([]interface{}); ok {
+		var matched = 0
+		for _, sub := range oneOf {
+			if len(validate(sub, v, path)) == 0 {
+				matched++
+			}
+		}
+		if matched != 1 {
+			fail("value matches %d schemas in oneOf, expected exactly one", matched)
+		}
+	}
+
+	if not, ok := schema["not"]; ok && len(validate(not, v, path)) == 0 {
+		fail("value must not match the schema in not")
+	}
+
+	return errs
+}
+
+// matchType reports whether the value matches the type (either a type name, or a list of names) given in schema
+func matchType(t interface{}, v interface{}) bool {
+	switch tt := t.(type) {
+	case string:
+		var actual = typeOf(v)
+		return actual == tt || (tt == "number" && actual == "integer")
+	case []interface{}:
+		for _, each := range tt {
+			if matchType(each, v) {
+				return true
+			}
+		}
+		return false
+	}
+	return true // invalid type keyword; allow everything
+}
+
+// typeOf returns the JSON Schema type name of the decoded json value
+func typeOf(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if val == math.Trunc(val) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return "unknown"
+}
+
+// number returns v as a float64 if it's a json number
+func number(v interface{}) (float64, bool) {
+	var n, ok = v.(float64)
+	return n, ok
+}
@@ -0,0 +1,59 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateJSONSchema(t *testing.T) {
+	var schema = []byte(`{
+		"type": "object",
+		"required": ["id", "name"],
+		"additionalProperties": false,
+		"properties": {
+			"id": {"type": "integer", "minimum": 1},
+			"name": {"type": "string", "minLength": 2, "pattern": "^[a-z]+$"},
+			"role": {"enum": ["admin", "user"]},
+			"tags": {"type": "array", "maxItems": 2, "items": {"type": "string"}},
+			"score": {"type": ["number", "null"], "exclusiveMaximum": 100},
+			"contact": {"oneOf": [{"type": "string"}, {"type": "object", "required": ["email"]}]}
+		}
+	}`)
+
+	var valid = []string{
+		`{"id": 1, "name": "alice"}`,
+		`{"id": 2, "name": "bob", "role": "admin", "tags": ["a", "b"], "score": 99.5, "contact": "x"}`,
+		`{"id": 3, "name": "carol", "score": null, "contact": {"email": "c@example.com"}}`,
+	}
+	for _, doc := range valid {
+		assert(t, ValidateJSONSchema(schema, []byte(doc)) == nil, "document must be valid: %s", doc)
+	}
+
+	var invalid = map[string]string{
+		`[]`:                                         "expected type object",
+		`{"name": "alice"}`:                          "missing required property 'id'",
+		`{"id": 1.5, "name": "alice"}`:               "$.id: expected type integer",
+		`{"id": 0, "name": "alice"}`:                 "less than minimum",
+		`{"id": 1, "name": "a"}`:                     "expected length of at least 2",
+		`{"id": 1, "name": "Alice"}`:                 "does not match pattern",
+		`{"id": 1, "name": "alice", "role": "root"}`: "is not one of",
+		`{"id": 1, "name": "alice", "tags": [1]}`:    "$.tags[0]: expected type string",
+		`{"id": 1, "name": "alice", "score": 100}`:   "is not less than 100",
+		`{"id": 1, "name": "alice", "age": 30}`:      "additional property 'age' is not allowed",
+		`{"id": 1, "name": "alice", "contact": {}}`:  "matches 0 schemas in oneOf",
+	}
+	for doc, msg := range invalid {
+		var err = ValidateJSONSchema(schema, []byte(doc))
+		assert(t, err != nil && strings.Contains(err.Error(), msg), "document %s must fail with '%s', got: %v", doc, msg, err)
+	}
+
+	t.Run("should report all violations", func(t *testing.T) {
+		var err = ValidateJSONSchema(schema, []byte(`{"id": 0, "name": "A"}`))
+		assert(t, err != nil && strings.HasPrefix(err.Error(), "multiple errors"), "must report all violations")
+	})
+
+	t.Run("should fail for malformed input", func(t *testing.T) {
+		assert(t, ValidateJSONSchema([]byte(`{`), []byte(`{}`)) != nil, "must fail for malformed schema")
+		assert(t, ValidateJSONSchema(schema, []byte(`{`)) != nil, "must fail for malformed document")
+	})
+}
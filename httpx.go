@@ -10,6 +10,7 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"time"
 )
 
 // ExecFn defines a function that can take an http.Request and return an http.Response (and optionally, an error).
@@ -37,6 +38,7 @@ type ExecFn func(*http.Request) (*http.Response, error)
 // in builders package for more details and how you can create a custom builder.
 func (fn ExecFn) MakeRequest(factory RequestFactory, builders ...RequestBuilder) Assertable {
 	var err error
+	var start = time.Now()
 
 	// build a new request and apply customisations
 	var request *http.Request
@@ -44,10 +46,13 @@ func (fn ExecFn) MakeRequest(factory RequestFactory, builders ...RequestBuilder)
 		return fail("httpx: failed to create request: %v", err)
 	}
 
+	var durations = make([]time.Duration, 0, len(builders))
 	for _, fn := range builders {
+		var begin = time.Now()
 		if err = fn(request); err != nil {
 			return fail("httpx: builder: %v", err)
 		}
+		durations = append(durations, time.Since(begin))
 	}
 
 	// execute the request
@@ -56,28 +61,68 @@ func (fn ExecFn) MakeRequest(factory RequestFactory, builders ...RequestBuilder)
 		return fail("httpx: failed to execute request: %v", err)
 	}
 
+	// pick up the tracer, if the request was executed by a traced ExecFn
+	var trace = tracerOf(response)
+	if trace != nil {
+		trace.start, trace.builders = start, durations
+	}
+
 	// return an Assertable to run assertions on response
 	return func(t TestingT, assertions ...Assertion) {
 		t.Helper()
 		defer response.Body.Close() // make sure to close the original response body always
 
-		// prepare a bytes.Buffer that'd allow us to seek to start after every assertion
-		// so that we can have multiple assertions that could read response's body
+		// read the body in a buffer so that it could be read by multiple assertions
 		var buf bytes.Buffer
 		if _, err := buf.ReadFrom(response.Body); err != nil {
 			t.Errorf("httpx: failed to read body into buffer: %v", err)
 			t.FailNow()
 		}
-		var reader = bytes.NewReader(buf.Bytes())
-		response.Body = ioutil.NopCloser(reader)
-
-		for _, fn := range assertions {
-			if err = fn(response); err != nil {
-				t.Errorf("httpx: assertion: %v", err)
-			}
-			_, _ = reader.Seek(0, io.SeekStart) // safe to ignore return values
+		expect(t, response, buf.Bytes(), trace, assertions)
+	}
+}
+
+// MakeRequestContext is like MakeRequest(...) but the request is made with the given context, instead of the one
+// set by the factory (which, for the ones provided by this package, is context.Background()). Use it to propagate
+// a per-test context carrying a deadline, cancellation or tracing data to the request.
+//
+//  var ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+//  defer cancel()
+//  WithDefaultClient().MakeRequestContext(ctx, Get("/versions")).ExpectIt(t, ToHaveStatus(http.StatusOK))
+func (fn ExecFn) MakeRequestContext(ctx context.Context, factory RequestFactory, builders ...RequestBuilder) Assertable {
+	return fn.MakeRequest(func() (*http.Request, error) {
+		var request, err = factory()
+		if err != nil {
+			return nil, err
+		}
+		return request.WithContext(ctx), nil
+	}, builders...)
+}
+
+// Execute is like MakeRequest(...) but executes the given, fully constructed, request instead of building
+// one using a RequestFactory. Use it to run requests built elsewhere (say, by another framework) through httpx.
+// Any builders are still applied to the request before it's executed.
+//
+//  var request = httptest.NewRequest(http.MethodGet, "/versions", nil)
+//  WithHandler(handler).Execute(request).ExpectIt(t, ToHaveStatus(http.StatusOK))
+func (fn ExecFn) Execute(request *http.Request, builders ...RequestBuilder) Assertable {
+	return fn.MakeRequest(func() (*http.Request, error) { return request, nil }, builders...)
+}
+
+// expect runs the assertions on response, with its body set to a reader over the given bytes
+// that is rewound after every assertion, so that multiple assertions could read response's body.
+func expect(t TestingT, response *http.Response, body []byte, trace *tracer, assertions []Assertion) {
+	t.Helper()
+	var reader = bytes.NewReader(body)
+	response.Body = ioutil.NopCloser(reader)
+
+	for _, fn := range assertions {
+		if err := trace.assert(fn, response); err != nil {
+			t.Errorf("httpx: assertion: %v", err)
 		}
+		_, _ = reader.Seek(0, io.SeekStart) // safe to ignore return values
 	}
+	trace.finish()
 }
 
 // Assertable defines a function that can take a slice of assertions and apply it on the response.
@@ -102,6 +147,18 @@ type Assertion func(*http.Response) error
 // RequestBuilder defines a function that customises the request before it's sent out.
 type RequestBuilder func(*http.Request) error
 
+// AndThen returns a RequestBuilder that applies fn and then next to the request,
+// stopping at the first error. It allows chaining builders inline, such as,
+//  WithHeader("Accept", "application/json").AndThen(WithBasicAuth("user", "$ecret"))
+func (fn RequestBuilder) AndThen(next RequestBuilder) RequestBuilder {
+	return func(request *http.Request) error {
+		if err := fn(request); err != nil {
+			return err
+		}
+		return next(request)
+	}
+}
+
 // RequestFactory defines a function capable of creating http.Request instances.
 // Use of this type allows us to decouple MakeRequest from the actual underlying
 // mechanism of building an http.Request. Implementations of this type could (say)
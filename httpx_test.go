@@ -2,7 +2,9 @@ package httpx_test
 
 import (
 	"bytes"
+	"context"
 	"errors"
+	"fmt"
 	. "go.riyazali.net/httpx"
 	"io"
 	"io/ioutil"
@@ -140,3 +142,109 @@ func TestReadResponseBodyMultipleTimes(t *testing.T) {
 		assert(t, 0 == r["FailNow"], "FailNow must not be called")
 	})
 }
+
+func TestRequestBuilder_AndThen(t *testing.T) {
+	var header = func(name string) RequestBuilder {
+		return func(request *http.Request) error {
+			request.Header.Add("X-Order", name)
+			return nil
+		}
+	}
+
+	t.Run("should apply builders in order", func(t *testing.T) {
+		var request, _ = http.NewRequest(http.MethodGet, "/", nil)
+		var err = header("a").AndThen(header("b")).AndThen(header("c"))(request)
+		assert(t, err == nil, "builder must not return error")
+		assert(t, reflect.DeepEqual(request.Header["X-Order"], []string{"a", "b", "c"}), "must apply builders in order")
+	})
+
+	t.Run("should stop at first error", func(t *testing.T) {
+		var request, _ = http.NewRequest(http.MethodGet, "/", nil)
+		var failing = RequestBuilder(func(*http.Request) error { return errors.New("test") })
+		var err = failing.AndThen(header("b"))(request)
+		assert(t, err != nil, "builder must return error")
+		assert(t, len(request.Header["X-Order"]) == 0, "must not apply next builder")
+	})
+}
+
+func TestExecFn_MakeRequestContext(t *testing.T) {
+	type key struct{}
+	var handler = func(writer http.ResponseWriter, request *http.Request) {
+		if request.Context().Err() != nil {
+			writer.WriteHeader(http.StatusRequestTimeout)
+			return
+		}
+		_, _ = io.WriteString(writer, fmt.Sprint(request.Context().Value(key{})))
+	}
+
+	var body = func(want string) Assertion {
+		return func(response *http.Response) error {
+			var b, _ = ioutil.ReadAll(response.Body)
+			if string(b) != want {
+				return errors.New("unexpected body: " + string(b))
+			}
+			return nil
+		}
+	}
+
+	t.Run("should use given context", func(t *testing.T) {
+		r := make(reporter)
+		var ctx = context.WithValue(context.Background(), key{}, "test-value")
+		handlerExec(handler).MakeRequestContext(ctx, Get("/")).ExpectIt(r, body("test-value"))
+		assert(t, 0 == r["Errorf"], "Errorf must not be called")
+	})
+
+	t.Run("should propagate cancellation", func(t *testing.T) {
+		r := make(reporter)
+		var ctx, cancel = context.WithCancel(context.Background())
+		cancel()
+		handlerExec(handler).MakeRequestContext(ctx, Get("/")).ExpectIt(r, func(response *http.Response) error {
+			if response.StatusCode != http.StatusRequestTimeout {
+				return errors.New("context not cancelled")
+			}
+			return nil
+		})
+		assert(t, 0 == r["Errorf"], "Errorf must not be called")
+	})
+
+	t.Run("should fail if factory fails", func(t *testing.T) {
+		r := make(reporter)
+		handlerExec(handler).MakeRequestContext(context.Background(), Using("bad method", "/", nil)).ExpectIt(r)
+		assert(t, 1 == r["Errorf"], "Errorf must be called")
+	})
+}
+
+func TestExecFn_Execute(t *testing.T) {
+	var handler = func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = io.WriteString(writer, request.Method+" "+request.URL.Path+" "+request.Header.Get("X-Extra"))
+	}
+
+	var body = func(want string) Assertion {
+		return func(response *http.Response) error {
+			var b, _ = ioutil.ReadAll(response.Body)
+			if string(b) != want {
+				return errors.New("unexpected body: " + string(b))
+			}
+			return nil
+		}
+	}
+
+	var extra = func(request *http.Request) error {
+		request.Header.Set("X-Extra", "yes")
+		return nil
+	}
+
+	r := make(reporter)
+	var request, _ = http.NewRequest(http.MethodPut, "/users/1", nil)
+	handlerExec(handler).Execute(request).ExpectIt(r, body("PUT /users/1 "))
+	handlerExec(handler).Execute(request, extra).ExpectIt(r, body("PUT /users/1 yes"))
+	assert(t, 0 == r["Errorf"], "Errorf must not be called")
+
+	// without tracing, the given request must be executed as-is (and not a copy of it)
+	var got *http.Request
+	ExecFn(func(r *http.Request) (*http.Response, error) {
+		got = r
+		return handlerExec(handler)(r)
+	}).Execute(request).ExpectIt(r)
+	assert(t, got == request, "must execute the given request")
+}
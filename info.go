@@ -0,0 +1,37 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+)
+
+// ResponseInfo holds details about how a response was received that aren't part of the response sent by the server.
+// It's recorded by some client options in the executors package and is available through ResponseInfoOf(...)
+type ResponseInfo struct {
+	// HeaderOrder holds the names of response's headers in the order they were received on the wire.
+	// It's only recorded by a client configured with executors.WithHeaderOrder().
+	HeaderOrder []string
+
+	// ConnectionReused reports whether the response was received over a reused connection.
+	// It's only recorded (and non-nil) with a client configured with executors.WithKeepAlive() (or executors.WithNoKeepAlive()).
+	ConnectionReused *bool
+}
+
+// responseInfoKey is the context key used to pass ResponseInfo along with the request
+type responseInfoKey struct{}
+
+// WithResponseInfo returns a copy of ctx carrying info. Executors record details in info while executing a request
+// made with the returned context, which are then available through ResponseInfoOf(...) on the response.
+func WithResponseInfo(ctx context.Context, info *ResponseInfo) context.Context {
+	return context.WithValue(ctx, responseInfoKey{}, info)
+}
+
+// ResponseInfoOf returns the ResponseInfo recorded for the response (through its request's context),
+// or nil if response has no request associated with it or no info was recorded.
+func ResponseInfoOf(response *http.Response) *ResponseInfo {
+	if response.Request == nil {
+		return nil
+	}
+	var info, _ = response.Request.Context().Value(responseInfoKey{}).(*ResponseInfo)
+	return info
+}
@@ -0,0 +1,81 @@
+package httpx
+
+import (
+	"fmt"
+	"sync"
+)
+
+// SafeT is a TestingT that can safely be used from multiple goroutines.
+//
+// testing.T doesn't allow calling FailNow from any goroutine other than the one running the test.
+// SafeT works around that by buffering all reported errors (and failure signal) which are then
+// replayed onto the wrapped TestingT by calling Flush() from the test's goroutine.
+//
+//  var st = ParallelSafeT(t)
+//  var wg sync.WaitGroup
+//  for i := 0; i < 10; i++ {
+//    wg.Add(1)
+//    go func() {
+//      defer wg.Done()
+//      WithHandler(handler).MakeRequest(Get("/")).ExpectIt(st, ToHaveStatus(http.StatusOK))
+//    }()
+//  }
+//  wg.Wait()
+//  st.Flush() // must be called on the test's goroutine
+//
+// Note that, unlike testing.T, FailNow on a SafeT does return and the calling goroutine carries on.
+type SafeT struct {
+	t TestingT
+
+	mu     sync.Mutex
+	errors []string
+
+	once   sync.Once
+	failed chan struct{}
+}
+
+// ParallelSafeT wraps t and returns a SafeT that can be shared between goroutines
+func ParallelSafeT(t TestingT) *SafeT {
+	return &SafeT{t: t, failed: make(chan struct{})}
+}
+
+// Errorf formats and buffers the error message
+func (s *SafeT) Errorf(format string, args ...interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errors = append(s.errors, fmt.Sprintf(format, args...))
+}
+
+// FailNow marks the test as failed and closes the channel returned by Failed()
+func (s *SafeT) FailNow() {
+	s.once.Do(func() { close(s.failed) })
+}
+
+// Helper is a no-op as helpers cannot be tracked across goroutines
+func (s *SafeT) Helper() {}
+
+// Failed returns a channel that is closed once FailNow is called
+func (s *SafeT) Failed() <-chan struct{} {
+	return s.failed
+}
+
+// Flush replays all buffered errors on the wrapped TestingT and calls FailNow on it if
+// FailNow was called on s. It must only be called from the goroutine running the test.
+func (s *SafeT) Flush() {
+	s.t.Helper()
+
+	s.mu.Lock()
+	var errors = s.errors
+	s.errors = nil
+	s.mu.Unlock()
+
+	for _, e := range errors {
+		s.t.Errorf("%s", e)
+	}
+
+	select {
+	case <-s.failed:
+		s.t.FailNow()
+	default:
+	}
+}
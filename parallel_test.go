@@ -0,0 +1,55 @@
+package httpx_test
+
+import (
+	. "go.riyazali.net/httpx"
+	"sync"
+	"testing"
+)
+
+func TestParallelSafeT(t *testing.T) {
+	t.Run("should buffer errors until flushed", func(t *testing.T) {
+		r := make(reporter)
+		var st = ParallelSafeT(r)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				st.Helper()
+				st.Errorf("error from goroutine %d", i)
+			}(i)
+		}
+		wg.Wait()
+
+		assert(t, 0 == r["Errorf"], "Errorf must not be called before flush")
+		st.Flush()
+		assert(t, 10 == r["Errorf"], "Errorf must be called for every buffered error")
+		assert(t, 0 == r["FailNow"], "FailNow must not be called")
+
+		st.Flush()
+		assert(t, 10 == r["Errorf"], "buffered errors must be replayed only once")
+	})
+
+	t.Run("should signal and replay FailNow", func(t *testing.T) {
+		r := make(reporter)
+		var st = ParallelSafeT(r)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func() { defer wg.Done(); st.FailNow() }()
+		}
+		wg.Wait()
+
+		select {
+		case <-st.Failed():
+		default:
+			t.Errorf("failed channel must be closed")
+		}
+
+		assert(t, 0 == r["FailNow"], "FailNow must not be called before flush")
+		st.Flush()
+		assert(t, 1 == r["FailNow"], "FailNow must be called exactly once")
+	})
+}
@@ -0,0 +1,113 @@
+package httpx
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+)
+
+// RecordedExchange captures a single request / response pair executed by a recording ExecFn.
+type RecordedExchange struct {
+	Method     string      // method of the request
+	URL        string      // url the request was sent to
+	StatusCode int         // status code of the received response
+	Header     http.Header // headers of the received response
+	Body       []byte      // body of the received response
+}
+
+// RecordedExchanges is an ordered collection of exchanges captured by an ExecFn returned by ExecFn.Recorded().
+// It is safe for concurrent use by multiple goroutines.
+type RecordedExchanges struct {
+	mu        sync.Mutex
+	exchanges []RecordedExchange
+}
+
+// Recorded returns an ExecFn that wraps fn and records every successful exchange made through it.
+// The returned RecordedExchanges can later be inspected or replayed using ReplayAsServer(...)
+//
+//  var exec, recorded = WithDefaultClient().Recorded()
+//  exec.MakeRequest(Get("https://httpbin.org/get")).ExpectIt(t, ToHaveStatus(http.StatusOK))
+func (fn ExecFn) Recorded() (ExecFn, *RecordedExchanges) {
+	var recorded = &RecordedExchanges{}
+	return func(request *http.Request) (*http.Response, error) {
+		var response, err = fn(request)
+		if err != nil || response == nil {
+			return response, err
+		}
+
+		// read the response body in memory and replace it with a copy so that assertions can still read it
+		var body []byte
+		if response.Body != nil {
+			defer response.Body.Close()
+			if body, err = ioutil.ReadAll(response.Body); err != nil {
+				return nil, err
+			}
+		}
+		response.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		recorded.mu.Lock()
+		defer recorded.mu.Unlock()
+		recorded.exchanges = append(recorded.exchanges, RecordedExchange{
+			Method:     request.Method,
+			URL:        request.URL.String(),
+			StatusCode: response.StatusCode,
+			Header:     response.Header.Clone(),
+			Body:       body,
+		})
+		return response, nil
+	}, recorded
+}
+
+// Exchanges returns a copy of all the exchanges recorded so far, in the order they were made.
+func (r *RecordedExchanges) Exchanges() []RecordedExchange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]RecordedExchange(nil), r.exchanges...)
+}
+
+// ReplayAsServer returns a running httptest.Server that serves the recorded exchanges.
+//
+// Each incoming request is matched to a recorded exchange using its method and request uri (path and query).
+// If an endpoint was recorded multiple times, the responses are replayed in the order they were recorded,
+// with the last one repeated once all others are exhausted. Any request that doesn't match a recorded exchange
+// is reported as an error on t and is responded with http.StatusNotImplemented.
+//
+// It is the caller's responsibility to Close() the returned server once done.
+func (r *RecordedExchanges) ReplayAsServer(t TestingT) *httptest.Server {
+	// group recorded exchanges by their method and request uri
+	var exchanges = make(map[string][]RecordedExchange)
+	for _, exchange := range r.Exchanges() {
+		var req, err = http.NewRequest(exchange.Method, exchange.URL, nil)
+		if err != nil {
+			continue // cannot happen as the url was originally parsed from a valid request
+		}
+		var key = req.Method + " " + req.URL.RequestURI()
+		exchanges[key] = append(exchanges[key], exchange)
+	}
+
+	var mu sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		mu.Lock()
+		var key = request.Method + " " + request.URL.RequestURI()
+		var candidates, ok = exchanges[key]
+		if !ok {
+			mu.Unlock()
+			t.Errorf("httpx: replay: no recorded exchange for %s", key)
+			writer.WriteHeader(http.StatusNotImplemented)
+			return
+		}
+		var exchange = candidates[0]
+		if len(candidates) > 1 {
+			exchanges[key] = candidates[1:]
+		}
+		mu.Unlock()
+
+		for name, values := range exchange.Header {
+			writer.Header()[name] = values
+		}
+		writer.WriteHeader(exchange.StatusCode)
+		_, _ = writer.Write(exchange.Body)
+	}))
+}
@@ -0,0 +1,65 @@
+package httpx_test
+
+import (
+	"fmt"
+	. "go.riyazali.net/httpx"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestExecFn_Recorded(t *testing.T) {
+	var counter = 0
+	var handler = func(writer http.ResponseWriter, request *http.Request) {
+		counter++
+		writer.Header().Set("X-Counter", fmt.Sprint(counter))
+		_, _ = fmt.Fprintf(writer, "%s %s", request.Method, request.URL.Path)
+	}
+
+	var exec, recorded = handlerExec(handler).Recorded()
+
+	t.Run("should record exchanges", func(t *testing.T) {
+		r := make(reporter)
+		exec.MakeRequest(Get("http://example.com/a?q=1")).ExpectIt(r, func(response *http.Response) error {
+			var body, _ = ioutil.ReadAll(response.Body)
+			assert(t, string(body) == "GET /a", "assertions must still be able to read the body")
+			return nil
+		})
+		exec.MakeRequest(Get("http://example.com/a?q=1")).ExpectIt(r)
+		exec.MakeRequest(Delete("http://example.com/b")).ExpectIt(r)
+
+		var exchanges = recorded.Exchanges()
+		assert(t, len(exchanges) == 3, "must record all exchanges")
+		assert(t, exchanges[0].Method == http.MethodGet && exchanges[0].URL == "http://example.com/a?q=1", "must record request")
+		assert(t, exchanges[2].StatusCode == http.StatusOK && string(exchanges[2].Body) == "DELETE /b", "must record response")
+	})
+
+	t.Run("should replay exchanges as server", func(t *testing.T) {
+		r := make(reporter)
+		var server = recorded.ReplayAsServer(r)
+		defer server.Close()
+
+		var get = func(method, path string) (*http.Response, string) {
+			var request, _ = http.NewRequest(method, server.URL+path, nil)
+			var response, err = http.DefaultClient.Do(request)
+			if err != nil {
+				t.Fatalf("failed to make request: %v", err)
+			}
+			defer response.Body.Close()
+			var body, _ = ioutil.ReadAll(response.Body)
+			return response, string(body)
+		}
+
+		var first, body = get(http.MethodGet, "/a?q=1")
+		assert(t, body == "GET /a" && first.Header.Get("X-Counter") == "1", "must replay first exchange")
+		var second, _ = get(http.MethodGet, "/a?q=1")
+		assert(t, second.Header.Get("X-Counter") == "2", "must replay exchanges in order")
+		var third, _ = get(http.MethodGet, "/a?q=1")
+		assert(t, third.Header.Get("X-Counter") == "2", "must repeat last exchange")
+		assert(t, 0 == r["Errorf"], "Errorf must not be called")
+
+		var unknown, _ = get(http.MethodPost, "/a?q=1")
+		assert(t, unknown.StatusCode == http.StatusNotImplemented, "must respond with 501 for unrecorded exchange")
+		assert(t, 1 == r["Errorf"], "Errorf must be called exactly once")
+	})
+}
@@ -0,0 +1,122 @@
+package httpx
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// BackoffStrategy decides how long to wait before retrying a request made through an ExecFn returned by ExecFn.WithRetry(...)
+type BackoffStrategy interface {
+	// NextDelay returns the delay before the next attempt, given the number of attempts made so far (starting at 1)
+	NextDelay(attempt int) time.Duration
+}
+
+// BackoffFunc is an adapter to allow the use of ordinary functions as a BackoffStrategy
+type BackoffFunc func(attempt int) time.Duration
+
+// NextDelay calls fn(attempt)
+func (fn BackoffFunc) NextDelay(attempt int) time.Duration { return fn(attempt) }
+
+// ExponentialBackoff returns a BackoffStrategy where the delay starts at base and is multiplied by multiplier
+// after every attempt, never exceeding max. If jitter is set, a random delay in [0, delay) is used instead,
+// to avoid many clients retrying in lockstep.
+func ExponentialBackoff(base time.Duration, multiplier float64, max time.Duration, jitter bool) BackoffStrategy {
+	return BackoffFunc(func(attempt int) time.Duration {
+		var d = float64(base) * math.Pow(multiplier, float64(attempt-1))
+		if d > float64(max) {
+			d = float64(max)
+		}
+		if jitter && d > 0 {
+			d = rand.Float64() * d
+		}
+		return time.Duration(d)
+	})
+}
+
+// LinearBackoff returns a BackoffStrategy where the delay grows by increment after every attempt, never exceeding max.
+func LinearBackoff(increment time.Duration, max time.Duration) BackoffStrategy {
+	return BackoffFunc(func(attempt int) time.Duration {
+		if d := increment * time.Duration(attempt); d < max {
+			return d
+		}
+		return max
+	})
+}
+
+// ConstantBackoff returns a BackoffStrategy that always waits for d.
+func ConstantBackoff(d time.Duration) BackoffStrategy {
+	return BackoffFunc(func(int) time.Duration { return d })
+}
+
+// WithRetry returns an ExecFn that wraps fn and retries a request, at most maxAttempts times in total, if it fails
+// with an error or a 5xx (or 429 Too Many Requests) response, waiting between attempts as decided by backoff.
+// The response (or error) of the last attempt is returned.
+//
+//  var exec = WithDefaultClient().WithRetry(5, ExponentialBackoff(100*time.Millisecond, 2, 2*time.Second, true))
+//
+// As retrying a request might duplicate its side effects on the server, only requests that are safe to retry are
+// retried; that is, the ones with an idempotent method (GET, HEAD, OPTIONS, TRACE, PUT or DELETE) or with an
+// Idempotency-Key (or X-Idempotency-Key) header, like net/http does. Other requests (like a plain POST) are made only once.
+//
+// Request body (if any) is read in memory once, unless the request provides GetBody, and resent with every attempt.
+func (fn ExecFn) WithRetry(maxAttempts int, backoff BackoffStrategy) ExecFn {
+	return func(request *http.Request) (*http.Response, error) {
+		if !idempotent(request) {
+			return fn(request)
+		}
+
+		var getBody = request.GetBody
+		if getBody == nil && request.Body != nil && request.Body != http.NoBody {
+			var body, err = ioutil.ReadAll(request.Body)
+			if err != nil {
+				return nil, err
+			}
+			_ = request.Body.Close()
+			getBody = func() (io.ReadCloser, error) { return ioutil.NopCloser(bytes.NewReader(body)), nil }
+		}
+
+		for attempt := 1; ; attempt++ {
+			var r = request.Clone(request.Context())
+			if getBody != nil {
+				var err error
+				if r.Body, err = getBody(); err != nil {
+					return nil, err
+				}
+			}
+
+			var response, err = fn(r)
+			var retry = err != nil || response.StatusCode >= 500 || response.StatusCode == http.StatusTooManyRequests
+			if !retry || attempt >= maxAttempts {
+				return response, err
+			}
+			if response != nil && response.Body != nil {
+				_, _ = io.Copy(ioutil.Discard, response.Body)
+				_ = response.Body.Close()
+			}
+
+			var timer = time.NewTimer(backoff.NextDelay(attempt))
+			select {
+			case <-request.Context().Done():
+				timer.Stop()
+				return nil, request.Context().Err()
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// idempotent reports whether the request is safe to retry
+func idempotent(request *http.Request) bool {
+	switch request.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	var _, key = request.Header["Idempotency-Key"]
+	var _, xKey = request.Header["X-Idempotency-Key"]
+	return key || xKey
+}
@@ -0,0 +1,94 @@
+package httpx_test
+
+import (
+	"errors"
+	. "go.riyazali.net/httpx"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBackoffStrategy(t *testing.T) {
+	t.Run("exponential", func(t *testing.T) {
+		var b = ExponentialBackoff(100*time.Millisecond, 2, time.Second, false)
+		assert(t, b.NextDelay(1) == 100*time.Millisecond, "must start at base")
+		assert(t, b.NextDelay(2) == 200*time.Millisecond, "must multiply delay")
+		assert(t, b.NextDelay(4) == 800*time.Millisecond, "must multiply delay")
+		assert(t, b.NextDelay(5) == time.Second, "must not exceed max")
+
+		var jittered = ExponentialBackoff(100*time.Millisecond, 2, time.Second, true)
+		for i := 0; i < 100; i++ {
+			var d = jittered.NextDelay(3)
+			assert(t, d >= 0 && d < 400*time.Millisecond, "jittered delay must be within range, got: %s", d)
+		}
+	})
+
+	t.Run("linear", func(t *testing.T) {
+		var b = LinearBackoff(100*time.Millisecond, 250*time.Millisecond)
+		assert(t, b.NextDelay(1) == 100*time.Millisecond, "must start at increment")
+		assert(t, b.NextDelay(2) == 200*time.Millisecond, "must grow by increment")
+		assert(t, b.NextDelay(3) == 250*time.Millisecond, "must not exceed max")
+	})
+
+	t.Run("constant", func(t *testing.T) {
+		var b = ConstantBackoff(time.Second)
+		assert(t, b.NextDelay(1) == time.Second && b.NextDelay(10) == time.Second, "must always return same delay")
+	})
+}
+
+func TestExecFn_WithRetry(t *testing.T) {
+	var attempts, bodies = 0, []string(nil)
+	var exec = ExecFn(func(request *http.Request) (*http.Response, error) {
+		attempts++
+		if request.Body != nil {
+			var body, _ = ioutil.ReadAll(request.Body)
+			bodies = append(bodies, string(body))
+		}
+		switch attempts {
+		case 1:
+			return nil, errors.New("connection refused")
+		case 2:
+			return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader("ok"))}, nil
+	})
+
+	var delays []int
+	var backoff = BackoffFunc(func(attempt int) time.Duration {
+		delays = append(delays, attempt)
+		return time.Millisecond
+	})
+
+	t.Run("should retry until success", func(t *testing.T) {
+		var request, _ = http.NewRequest(http.MethodPost, "/", nil)
+		request.Body = ioutil.NopCloser(strings.NewReader("hello")) // no GetBody, so it must be buffered
+		request.Header.Set("Idempotency-Key", "8e03978e")
+		var response, err = exec.WithRetry(5, backoff)(request)
+		assert(t, err == nil && response.StatusCode == http.StatusOK, "must return successful response")
+		assert(t, attempts == 3, "must stop retrying after success, got: %d", attempts)
+		assert(t, len(delays) == 2 && delays[0] == 1 && delays[1] == 2, "must wait between attempts, got: %v", delays)
+		assert(t, len(bodies) == 3 && bodies[0] == "hello" && bodies[2] == "hello", "must resend body, got: %v", bodies)
+	})
+
+	t.Run("should give up after max attempts", func(t *testing.T) {
+		attempts, delays = 0, nil
+		var request, _ = http.NewRequest(http.MethodGet, "/", nil)
+		var _, err = exec.WithRetry(1, backoff)(request)
+		assert(t, err != nil, "must return error of last attempt")
+		assert(t, attempts == 1 && len(delays) == 0, "must not retry")
+
+		attempts = 0
+		var response, _ = exec.WithRetry(2, backoff)(request)
+		assert(t, response.StatusCode == http.StatusServiceUnavailable, "must return response of last attempt")
+	})
+
+	t.Run("should not retry non-idempotent requests", func(t *testing.T) {
+		attempts, delays = 0, nil
+		var request, _ = http.NewRequest(http.MethodPost, "/", strings.NewReader("hello"))
+		var _, err = exec.WithRetry(5, backoff)(request)
+		assert(t, err != nil, "must return error of the only attempt")
+		assert(t, attempts == 1 && len(delays) == 0, "must not retry, got: %d attempts", attempts)
+	})
+}
@@ -0,0 +1,97 @@
+package httpx
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// TraceContext records timing metrics of a request made through an ExecFn returned by ExecFn.Traced().
+// It always holds metrics for the most recent request and isn't safe for concurrent use.
+type TraceContext struct {
+	BuilderDurations   []time.Duration // time taken by each RequestBuilder, in order
+	ExecDuration       time.Duration   // time taken by the ExecFn to execute the request
+	AssertionDurations []time.Duration // time taken by each Assertion, in order
+	TotalDuration      time.Duration   // total time taken from creating the request till the last assertion
+}
+
+// Traced returns an ExecFn that wraps fn and records timing metrics of every request made through it.
+// Use it to find out which builder or assertion in a test is slow.
+//
+// The ExecFn passes a tracer along with the request's context, which MakeRequest(...) picks up from
+// the response's request to record metrics for the builders and assertions, so other ExecFn are left unaffected.
+//
+//  var exec, trace = WithDefaultClient().Traced()
+//  exec.MakeRequest(Get("https://httpbin.org/get")).ExpectIt(t, ToHaveStatus(http.StatusOK))
+//  trace.Print(os.Stdout)
+func (fn ExecFn) Traced() (ExecFn, *TraceContext) {
+	var tc = &TraceContext{}
+	return func(request *http.Request) (*http.Response, error) {
+		var t = &tracer{start: time.Now(), sink: tc}
+		request = request.WithContext(context.WithValue(request.Context(), tracerKey{}, t))
+
+		var response, err = fn(request)
+		t.exec = time.Since(t.start)
+		if response != nil && response.Request == nil {
+			response.Request = request // in-memory handlers don't set the request, which carries the tracer
+		}
+		return response, err
+	}, tc
+}
+
+// Print writes a human readable report of the recorded metrics to w
+func (tc *TraceContext) Print(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "httpx: trace: total %s\n", tc.TotalDuration)
+	for i, d := range tc.BuilderDurations {
+		_, _ = fmt.Fprintf(w, "  builder #%d: %s\n", i, d)
+	}
+	_, _ = fmt.Fprintf(w, "  exec: %s\n", tc.ExecDuration)
+	for i, d := range tc.AssertionDurations {
+		_, _ = fmt.Fprintf(w, "  assertion #%d: %s\n", i, d)
+	}
+}
+
+// tracerKey is the context key used by a traced ExecFn to pass the tracer along with the request
+type tracerKey struct{}
+
+// tracer collects metrics during MakeRequest(...) and writes them to sink (if set by a traced ExecFn)
+type tracer struct {
+	start      time.Time
+	builders   []time.Duration
+	exec       time.Duration
+	assertions []time.Duration
+	sink       *TraceContext
+}
+
+// tracerOf returns the tracer passed along with the response's request by a traced ExecFn, or nil if there's none
+func tracerOf(response *http.Response) *tracer {
+	if response == nil || response.Request == nil {
+		return nil
+	}
+	var t, _ = response.Request.Context().Value(tracerKey{}).(*tracer)
+	return t
+}
+
+// assert runs fn on response, recording the time it took (if t is not nil)
+func (t *tracer) assert(fn Assertion, response *http.Response) error {
+	if t == nil {
+		return fn(response)
+	}
+	var start = time.Now()
+	defer func() { t.assertions = append(t.assertions, time.Since(start)) }()
+	return fn(response)
+}
+
+// finish writes the collected metrics to the sink
+func (t *tracer) finish() {
+	if t != nil && t.sink != nil {
+		*t.sink = TraceContext{
+			BuilderDurations:   t.builders,
+			ExecDuration:       t.exec,
+			AssertionDurations: t.assertions,
+			TotalDuration:      time.Since(t.start),
+		}
+	}
+}
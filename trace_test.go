@@ -0,0 +1,49 @@
+package httpx_test
+
+import (
+	"bytes"
+	. "go.riyazali.net/httpx"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecFn_Traced(t *testing.T) {
+	var handler = func(writer http.ResponseWriter, request *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	var noopBuilder = func(*http.Request) error { return nil }
+	var slowAssertion = func(*http.Response) error { time.Sleep(10 * time.Millisecond); return nil }
+
+	t.Run("should record metrics", func(t *testing.T) {
+		var exec, trace = handlerExec(handler).Traced()
+
+		r := make(reporter)
+		exec.MakeRequest(Get("http://example.com"), noopBuilder, noopBuilder).ExpectIt(r, slowAssertion)
+		assert(t, 0 == r["Errorf"], "Errorf must not be called")
+
+		assert(t, len(trace.BuilderDurations) == 2, "must record duration for every builder")
+		assert(t, trace.ExecDuration >= 10*time.Millisecond, "must record exec duration")
+		assert(t, len(trace.AssertionDurations) == 1 && trace.AssertionDurations[0] >= 10*time.Millisecond, "must record assertion duration")
+		assert(t, trace.TotalDuration >= trace.ExecDuration+trace.AssertionDurations[0], "must record total duration")
+
+		var buf bytes.Buffer
+		trace.Print(&buf)
+		assert(t, strings.Contains(buf.String(), "builder #1") && strings.Contains(buf.String(), "assertion #0"), "must print report")
+	})
+
+	t.Run("should record concurrent requests", func(t *testing.T) {
+		var exec, trace = handlerExec(handler).Traced()
+		exec.Concurrent(2, Get("http://example.com")).ExpectAll(make(reporter), slowAssertion)
+		assert(t, trace.ExecDuration >= 10*time.Millisecond, "must record exec duration")
+		assert(t, len(trace.AssertionDurations) == 1, "must record assertion duration")
+	})
+
+	t.Run("should not record requests made through other ExecFn", func(t *testing.T) {
+		var _, trace = handlerExec(handler).Traced()
+		handlerExec(handler).MakeRequest(Get("http://example.com")).ExpectIt(make(reporter))
+		assert(t, trace.TotalDuration == 0, "must not record any metrics")
+	})
+}